@@ -1,28 +1,26 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(acquiredCompanyEntry))
+	registerType(new(acquiredCompanyEntry))
 }
 
-func (g *Game) startAcquisitions(c *gin.Context, cu *user.User) {
+func (g *Game) startAcquisitions() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = Acquisitions
 	g.beginningOfPhaseReset()
-	if np := g.acquisitionsNextPlayer(cu, g.Players()[g.NumPlayers-1]); np == nil {
-		g.startResearch(c)
+	if np := g.acquisitionsNextPlayer(g.Players()[g.NumPlayers-1]); np == nil {
+		g.startResearch()
 	} else {
 		g.setCurrentPlayers(np)
 	}
@@ -40,7 +38,7 @@ func (g *Game) SelectedShippingCompany() *Company {
 	return g.ShippingCompanies()[g.SelectedShippingProvince]
 }
 
-func (g *Game) acquireCompany(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) acquireCompany(p *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -50,16 +48,15 @@ func (g *Game) acquireCompany(c *gin.Context, cu *user.User) (tmpl string, err e
 		sIndex, dIndex int
 	)
 
-	if s, sIndex, d, dIndex, err = g.validateAcquireCompany(c, cu); err != nil {
+	if s, sIndex, d, dIndex, err = g.validateAcquireCompany(p); err != nil {
 		tmpl = "indonesia/flash_notice"
 		return
 	}
 
-	cp := g.CurrentPlayer()
-	s.Company = newCompany(g, cp, sIndex, d)
+	s.Company = newCompany(g, p, sIndex, d)
 
 	// Cache SelectedSlot, SelectedPlayerID so SelectedCompany works.
-	g.setSelectedPlayer(cp)
+	g.setSelectedPlayer(p)
 	g.SelectedSlot = sIndex
 	g.AvailableDeeds = g.AvailableDeeds.removeAt(dIndex)
 	if s.Company.IsProductionCompany() {
@@ -72,16 +69,15 @@ func (g *Game) acquireCompany(c *gin.Context, cu *user.User) (tmpl string, err e
 	return
 }
 
-func (g *Game) validateAcquireCompany(c *gin.Context, cu *user.User) (s *Slot, sIndex int, d *Deed, dIndex int, err error) {
+func (g *Game) validateAcquireCompany(p *Player) (s *Slot, sIndex int, d *Deed, dIndex int, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if err = g.validatePlayerAction(cu); err != nil {
+	if err = g.validatePlayerAction(p); err != nil {
 		return
 	}
 
-	cp := g.CurrentPlayer()
-	s, sIndex = cp.getEmptySlot()
+	s, sIndex = p.getEmptySlot()
 	d, dIndex = g.SelectedDeed(), g.SelectedDeedIndex
 
 	switch {
@@ -89,42 +85,44 @@ func (g *Game) validateAcquireCompany(c *gin.Context, cu *user.User) (s *Slot, s
 		err = sn.NewVError("You must select deed.")
 	case s == nil:
 		err = sn.NewVError("You do not have a free slot for the company.")
+	case d.Goods != Shipping && !g.canStartGoodsInProvince(d.Goods, d.Province):
+		// Companies acquired earlier in the phase may have taken the last area to start in.
+		err = sn.NewVError("There is no room to start the selected company.")
 	}
 	return
 }
 
-func (g *Game) placeInitialProduct(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) placeInitialProduct(p *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	a, com, err := g.validateplaceInitialProduct(c, cu)
+	a, com, err := g.validateplaceInitialProduct(p)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
 
-	cp := g.CurrentPlayer()
-	cp.PerformedAction = true
+	p.PerformedAction = true
 	a.AddProducer(com)
 	com.AddArea(a)
 
 	// Log placement
-	e := g.newAcquiredCompanyEntryFor(cp, com)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := g.newAcquiredCompanyEntryFor(p, com)
+	g.emit(e)
 
 	// Reset SubPhase
 	g.SubPhase = NoSubPhase
 	return "indonesia/placed_product_update", nil
 }
 
-func (g *Game) validateplaceInitialProduct(c *gin.Context, cu *user.User) (*Area, *Company, error) {
+func (g *Game) validateplaceInitialProduct(p *Player) (*Area, *Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	a, com, err := g.SelectedArea(), g.SelectedCompany(), g.validatePlayerAction(cu)
+	a, com, err := g.SelectedArea(), g.SelectedCompany(), g.validatePlayerAction(p)
 	switch {
 	case err != nil:
 		return nil, nil, err
-	case c == nil:
+	case com == nil:
 		return nil, nil, sn.NewVError("You must acquire a company first.")
 	case a == nil:
 		return nil, nil, sn.NewVError("You must select an area for the %s token.", com.Goods())
@@ -164,33 +162,32 @@ func (e *acquiredCompanyEntry) HTML(c *gin.Context) template.HTML {
 		g.NameByPID(e.PlayerID), e.Deed.Goods, e.Deed.Province)
 }
 
-func (g *Game) placeInitialShip(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) placeInitialShip(p *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	a, com, err := g.validateplaceInitialShip(c, cu)
+	a, com, err := g.validateplaceInitialShip(p)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
 
-	cp := g.CurrentPlayer()
-	cp.PerformedAction = true
+	p.PerformedAction = true
 	com.AddShipIn(a)
 
 	// Log placement
-	e := g.newAcquiredCompanyEntryFor(cp, com)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := g.newAcquiredCompanyEntryFor(p, com)
+	g.emit(e)
 
 	// Reset SubPhase
 	g.SubPhase = NoSubPhase
 	return "indonesia/placed_product_update", nil
 }
 
-func (g *Game) validateplaceInitialShip(c *gin.Context, cu *user.User) (*Area, *Company, error) {
+func (g *Game) validateplaceInitialShip(p *Player) (*Area, *Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(p)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -200,7 +197,7 @@ func (g *Game) validateplaceInitialShip(c *gin.Context, cu *user.User) (*Area, *
 	case com == nil:
 		return nil, nil, sn.NewVError("You must acquire a company first.")
 	case a == nil:
-		return nil, nil, sn.NewVError("You must select an area for the %s token.", com.Goods)
+		return nil, nil, sn.NewVError("You must select an area for the %s token.", com.Goods())
 	case !a.IsSea():
 		return nil, nil, sn.NewVError("You must select a sea area.")
 	case !a.adjacentToProvince(com.Deeds[0].Province):
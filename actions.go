@@ -1,26 +1,30 @@
 package indonesia
 
 import (
+	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/sn"
 	"github.com/SlothNinja/user"
 )
 
-func (g *Game) validatePlayerAction(cu *user.User) error {
+func (g *Game) validatePlayerAction(p *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	cp := g.CurrentPlayer()
 	switch {
-	case cp.PerformedAction:
-		return sn.NewVError("You have already performed an action.")
-	case !g.IsCurrentPlayer(cu):
+	case p == nil, !g.isCurrentPlayer(p):
 		return sn.NewVError("Only the current player can perform an action.")
+	case p.PerformedAction:
+		return sn.NewVError("You have already performed an action.")
 	default:
 		return nil
 	}
 }
 
+func (g *Game) isCurrentPlayer(p *Player) bool {
+	return p != nil && g.Status != game.Completed && g.CPUserIndices.Include(p.ID())
+}
+
 func (g *Game) validateAdminAction(cu *user.User) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
@@ -0,0 +1,391 @@
+package indonesia
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/restful"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSuggestions caps the suggestions returned by Advise.
+const maxSuggestions = 5
+
+// Suggestion is an action recommended by the move advisor.
+// Command gives the action in the notation of game records.
+type Suggestion struct {
+	Action  string  `json:"action"`
+	Reason  string  `json:"reason"`
+	Command string  `json:"command"`
+	Score   int     `json:"score"`
+	cmd     Command `json:"-"`
+}
+
+// Advise returns the botActions of the player having id pid, best first,
+// each with a short reason.  Actions are rated by the same rules of thumb
+// that the HeuristicBot plays by, and each reason gives the facts its rating
+// weighs and how it compares with the other actions.
+func (g *Game) Advise(pid int) []Suggestion {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	p := g.PlayerByID(pid)
+	var ss []Suggestion
+	for _, cmd := range botActions(g, pid) {
+		record, err := formatCommand(cmd)
+		if err != nil {
+			continue
+		}
+		action, reason := g.explain(p, cmd)
+		ss = append(ss, Suggestion{
+			Action:  action,
+			Reason:  reason,
+			Command: record,
+			Score:   scoreAction(g, p, cmd),
+			cmd:     cmd,
+		})
+	}
+
+	sort.SliceStable(ss, func(i, j int) bool { return ss[i].Score > ss[j].Score })
+	for i := range ss {
+		ss[i].Reason = joinReasons(ss[i].Reason, rankReason(ss, i))
+	}
+	if len(ss) > maxSuggestions {
+		ss = ss[:maxSuggestions]
+	}
+	return ss
+}
+
+// rankReason compares the rating of ss[i] with the best of the other suggestions,
+// which are sorted best first.
+func rankReason(ss []Suggestion, i int) string {
+	if len(ss) == 1 {
+		return "It is your only legal action."
+	}
+
+	j := 0
+	if i == 0 {
+		j = 1
+	}
+	other := lowerFirst(ss[j].Action)
+	if ss[j].Action == ss[i].Action {
+		other = "another option"
+	}
+
+	switch d := ss[i].Score - ss[j].Score; {
+	case d == 0:
+		return fmt.Sprintf("It is rated %d, the same as %s.", ss[i].Score, other)
+	case d > 0:
+		return fmt.Sprintf("It is rated %d, %d above %s.", ss[i].Score, d, other)
+	default:
+		return fmt.Sprintf("It is rated %d, %d below %s.", ss[i].Score, -d, other)
+	}
+}
+
+func joinReasons(reason, rank string) string {
+	if reason == "" {
+		return rank
+	}
+	return reason + " " + rank
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// explain describes cmd and the facts its rating for p weighs.
+func (g *Game) explain(p *Player, cmd Command) (string, string) {
+	switch cmd := cmd.(type) {
+	case FinishTurn:
+		return "Finish your turn", ""
+	case Pass:
+		return "Pass", fmt.Sprintf("You keep your Rp %d.", p.Rupiah)
+	case PlayCard:
+		return fmt.Sprintf("Play city card %d", cmd.Card+1), "A city card must be played to place a new city."
+	case PlaceCity:
+		a := g.GetArea(cmd.Area)
+		return fmt.Sprintf("Place a city in %s", a.Province()),
+			fmt.Sprintf("The area borders %d land and %d sea areas.", len(a.AdjacentLandAreas()), len(a.AdjacentSeaAreas()))
+	case TurnOrderBid:
+		action := fmt.Sprintf("Bid Rp %d for turn order", cmd.Bid)
+		if cmd.Bid == 0 {
+			action = "Bid nothing for turn order"
+		}
+		return action, turnOrderBidReason(p, cmd.Bid)
+	case AnnounceMerger:
+		c := g.PlayerByID(cmd.OwnerID).Slots[cmd.Slot-1].Company
+		return fmt.Sprintf("Announce a merger of the %s company", c), mergerScoreReason(scoreAnnounceMerger(g, p, cmd))
+	case AnnounceMergerPartner:
+		c := g.PlayerByID(cmd.OwnerID).Slots[cmd.Slot-1].Company
+		return fmt.Sprintf("Merge with the %s company", c), mergerScoreReason(scoreMergerPartner(g, p, cmd))
+	case MergerBid:
+		if cmd.Bid == NoBid {
+			return "Pass on this merger", fmt.Sprintf("You keep your Rp %d.", p.Rupiah)
+		}
+		value := mergerValue(p, g.Merger, cmd.Bid)
+		return fmt.Sprintf("Bid Rp %d on this merger", cmd.Bid),
+			fmt.Sprintf("The merged company is estimated to be worth Rp %d to you, %s.", value, difference(value-cmd.Bid, "the bid"))
+	case RemoveRiceSpice:
+		a := g.GetArea(cmd.Area)
+		return fmt.Sprintf("Remove the goods in %s", a.Province()),
+			fmt.Sprintf("The area borders %d cities.", len(a.AdjacentCityAreas()))
+	case RemoveRiceSpiceSet:
+		set := g.SiapFajiMerger.removalSetOf(cmd.Areas)
+		return "Remove the goods in " + g.provincesOf(cmd.Areas),
+			fmt.Sprintf("The Siap Faji company keeps %d zones able to deliver %d goods.", set.Zones, set.Deliveries)
+	case AcquireCompany:
+		d := g.AvailableDeeds[cmd.Deed]
+		if d.Goods == Shipping {
+			return fmt.Sprintf("Acquire the %s Shipping deed", d.Province),
+				fmt.Sprintf("It allows up to %d ships this era, and %d of %d players run a shipping company.",
+					d.MaxShips[g.Era], len(g.ShippingCompanies()), len(g.Players()))
+		}
+		cities, shipped := g.provinceNeighbours(d.Province)
+		return fmt.Sprintf("Acquire the %s %s deed", d.Province, d.Goods),
+			fmt.Sprintf("%s sells for Rp %d, and the areas of %s border %d cities and %d sea areas with ships.",
+				d.Goods, d.Goods.Price(), d.Province, cities, shipped)
+	case PlaceInitialProduct:
+		a := g.GetArea(cmd.Area)
+		return fmt.Sprintf("Produce in %s", a.Province()),
+			fmt.Sprintf("The area borders %d cities and %d sea areas.", len(a.AdjacentCityAreas()), len(a.AdjacentSeaAreas()))
+	case PlaceInitialShip:
+		return "Place your first ship next to " + g.provincesNear(cmd.Area), seaAreaReason(g.GetArea(cmd.Area))
+	case ConductResearch:
+		return fmt.Sprintf("Research %s", cmd.Technology), researchReason(g, p, cmd.Technology)
+	case SelectHullPlayer:
+		if cmd.PlayerID == p.ID() {
+			return "Increase your own hull size", "Larger hulls let your ships carry more goods."
+		}
+		reason := "The hull size of a rival grows instead of your own."
+		if p.Technologies[HullTech] == 5 {
+			reason = "Your own hull size is already at its maximum."
+		}
+		return fmt.Sprintf("Increase the hull size of %s", g.NameByPID(cmd.PlayerID)), reason
+	case OperateCompany:
+		c := p.Slots[cmd.Slot-1].Company
+		unit := "goods"
+		if c.IsShippingCompany() {
+			unit = "ships"
+		}
+		return fmt.Sprintf("Operate the %s company", c), fmt.Sprintf("The company has %d %s.", size(c), unit)
+	case AcceptProposedFlow:
+		return "Deliver along the proposed plan", g.deliveryReason(p)
+	case AcceptAlternativeFlow:
+		return fmt.Sprintf("Deliver along alternative plan %d", cmd.Plan+1),
+			g.alternativeReason(p, g.AlternativePaths[cmd.Plan])
+	case SelectGood, SelectShip, SelectCity:
+		return "Deliver goods step by step", "You choose each delivery yourself instead of accepting a plan."
+	case ExpandProduction:
+		return fmt.Sprintf("Expand production into %s", g.GetArea(cmd.Area).Province()), expansionReason(g, p)
+	case ExpandShipping:
+		return "Expand shipping next to " + g.provincesNear(cmd.Area), seaAreaReason(g.GetArea(cmd.Area))
+	case StopExpanding:
+		return "Stop expanding", ""
+	case GrowCities:
+		return "Grow the selected cities", "Every selection using the available city stones is rated the same."
+	default:
+		return fmt.Sprintf("%T", cmd), ""
+	}
+}
+
+// turnOrderBidReason compares bid with the bid the advisor expects to outbid the rivals of p.
+func turnOrderBidReason(p *Player, bid int) string {
+	target := turnOrderBidFor(p)
+	total := fmt.Sprintf("At ×%d it totals %d.", p.Multiplier(), bid*p.Multiplier())
+	switch {
+	case target == 0 && bid == 0:
+		return "Outbidding the likely bids of your rivals would cost more than a tenth of your rupiah."
+	case target == 0:
+		return total + " Outbidding the likely bids of your rivals would cost more than a tenth of your rupiah."
+	case bid == target:
+		return total + " It is the least bid expected to outbid the likely bids of your rivals."
+	default:
+		return total + fmt.Sprintf(" The least bid expected to outbid the likely bids of your rivals is Rp %d.", target)
+	}
+}
+
+// mergerScoreReason describes the rating of announcing a merger, which is the
+// estimated worth of the merged company less the nominal bid.
+func mergerScoreReason(score int) string {
+	if score < 0 {
+		return "The nominal bid is more than half your rupiah."
+	}
+	return fmt.Sprintf("The merged company is estimated to be worth %s.", difference(score, "the nominal bid"))
+}
+
+// difference describes the difference d between a worth and what it is compared with.
+func difference(d int, with string) string {
+	switch {
+	case d > 0:
+		return fmt.Sprintf("Rp %d more than %s", d, with)
+	case d < 0:
+		return fmt.Sprintf("Rp %d less than %s", -d, with)
+	default:
+		return "as much as " + with
+	}
+}
+
+// provinceNeighbours counts the cities bordering the areas of province, and
+// the sea areas with ships bordering them, as often as each borders an area.
+func (g *Game) provinceNeighbours(province Province) (int, int) {
+	var cities, shipped int
+	for _, a := range g.areasInProvince(province) {
+		cities += len(a.AdjacentCityAreas())
+		for _, sea := range a.AdjacentSeaAreas() {
+			if sea.hasAShipper() {
+				shipped++
+			}
+		}
+	}
+	return cities, shipped
+}
+
+// seaAreaReason describes the producers and cities bordering the sea area a.
+func seaAreaReason(a *Area) string {
+	var producers, cities int
+	for _, land := range a.AdjacentLandAreas() {
+		switch {
+		case land.hasProducer():
+			producers++
+		case land.hasCity():
+			cities++
+		}
+	}
+	return fmt.Sprintf("The sea area borders %d producers and %d cities.", producers, cities)
+}
+
+// deliveryReason reports the income p earns by accepting the proposed delivery plan.
+func (g *Game) deliveryReason(p *Player) string {
+	sim, err := g.Clone()
+	if err != nil {
+		return ""
+	}
+	before := sim.PlayerByID(p.ID()).Rupiah
+	if _, err := sim.Apply(p.ID(), AcceptProposedFlow{}); err != nil {
+		return ""
+	}
+	income := sim.PlayerByID(p.ID()).Rupiah - before
+	return fmt.Sprintf("It earns you Rp %d and feeds the cities in %s.", income, g.fedCities(g.ProposedPath))
+}
+
+// alternativeReason describes the cities fed by fm and the ships of other players it pays.
+func (g *Game) alternativeReason(p *Player, fm flowMatrix) string {
+	reason := fmt.Sprintf("It feeds the cities in %s", g.fedCities(fm))
+	if others := fm.ships().OtherShips(p.ID()); others > 0 {
+		return reason + fmt.Sprintf(", paying for %d goods carried on other players' ships.", others)
+	}
+	return reason + ", using only your own ships."
+}
+
+func researchReason(g *Game, p *Player, t Technology) string {
+	level := fmt.Sprintf("Your %s is at level %d.", t, p.Technologies[t])
+	switch t {
+	case SlotsTech:
+		if !p.hasEmptySlot() {
+			return level + " Every slot holds a company, so you can not acquire another."
+		}
+		return level + " More slots let you run more companies."
+	case ExpansionsTech:
+		return level + " Companies that expand further produce and carry more."
+	case MergersTech:
+		return level + fmt.Sprintf(" %d companies are in play among %d players.", len(g.Companies()), len(g.Players()))
+	case HullTech:
+		return level + " Larger hulls let each ship carry more goods."
+	default:
+		return level + fmt.Sprintf(" Your turn order bids would count ×%d.", bidMultiplier[p.Technologies[BidMultiplierTech]+1])
+	}
+}
+
+func expansionReason(g *Game, p *Player) string {
+	c := g.SelectedCompany()
+	switch {
+	case g.SubPhase == OPFreeExpansion:
+		return "This expansion is free."
+	case c == nil:
+		return ""
+	case !c.deliveredAllGoods():
+		return fmt.Sprintf("The company did not deliver all its goods, and expanding costs Rp %d.", c.Goods().Price())
+	default:
+		return fmt.Sprintf("The company delivered all its goods, and expanding costs Rp %d of your Rp %d.", c.Goods().Price(), p.Rupiah)
+	}
+}
+
+// fedCities names the provinces of the cities fed by fm.
+func (g *Game) fedCities(fm flowMatrix) string {
+	var names []string
+	for aid := range fm.cities() {
+		if name := g.GetArea(aid).Province().String(); !includeString(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return restful.ToSentence(names)
+}
+
+// provincesNear names the provinces bordering the sea area having id aid.
+func (g *Game) provincesNear(aid AreaID) string {
+	var names []string
+	for _, a := range g.GetArea(aid).AdjacentLandAreas() {
+		name := a.Province().String()
+		if !includeString(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "open sea"
+	}
+	return restful.ToSentence(names)
+}
+
+// provincesOf names the provinces of the areas of ids.
+func (g *Game) provincesOf(ids AreaIDS) string {
+	var names []string
+	for _, id := range ids {
+		name := g.GetArea(id).Province().String()
+		if !includeString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return restful.ToSentence(names)
+}
+
+func includeString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// advise responds with the suggestions for the current user, who must be a current player.
+func (client *Client) advise(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g := gameFrom(c)
+	if g == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	cu, err := client.User.Current(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	pid := g.playerIDFor(cu)
+	if !g.isCurrentPlayer(g.PlayerByID(pid)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the current player may ask for advice."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": g.Advise(pid)})
+}
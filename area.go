@@ -318,9 +318,13 @@ func (ids AreaIDS) addUnique(aids ...AreaID) AreaIDS {
 	return ids
 }
 
-func (g *Game) adjacentAreaIDS(aid AreaID) (ids AreaIDS) {
+func (g *Game) adjacentAreaIDS(aid AreaID) AreaIDS {
+	return adjacentAreaIDSFor(g.Version, aid)
+}
+
+func adjacentAreaIDSFor(version int, aid AreaID) (ids AreaIDS) {
 	ids = adjacentAreasMap[aid]
-	if g.Version == 2 {
+	if version == 2 {
 		switch aid {
 		case JawaBarat37:
 			ids = AreaIDS{JawaBarat36, JawaBarat38, JawaBarat39, JawaTengah136, Sea131}
@@ -350,42 +354,17 @@ func (g *Game) adjacentAreaIDS(aid AreaID) (ids AreaIDS) {
 	return
 }
 
-func (g *Game) adjacentTo(ids AreaIDS, aid AreaID) bool {
-	aids := g.adjacentAreaIDS(aid)
-	for _, id := range ids {
-		if aids.include(id) {
-			return true
-		}
-	}
-	return false
-}
-
+// contiguous reports whether the areas having ids form one connected group.
 func (g *Game) contiguous(ids AreaIDS) bool {
-	switch l := len(ids); {
-	case l < 1:
+	if len(ids) == 0 {
 		return false
-	case l == 1:
-		return true
-	default:
-		cids := make(AreaIDS, l-1)
-		copy(cids, ids[1:])
-		return g.contiguousRecursive(AreaIDS{ids[0]}, cids)
 	}
-}
-
-func (g *Game) contiguousRecursive(ids, aids AreaIDS) bool {
-	l := len(aids)
-	for _, id := range aids {
-		if g.adjacentTo(ids, id) {
-			if l == 1 {
-				return true
-			}
-			as := append(ids, id)
-			is := aids.remove(id)
-			return g.contiguousRecursive(as, is)
-		}
+	group := setOf(ids...)
+	reached := setOf(ids[0])
+	for frontier := reached; !frontier.empty(); reached = reached.union(frontier) {
+		frontier = g.neighborhood(frontier).intersect(group).minus(reached)
 	}
-	return false
+	return reached == group
 }
 
 const (
@@ -564,16 +543,18 @@ const (
 	SeaLast   AreaID = Sea135
 )
 
-func (g *Game) landIDS() (ids AreaIDS) {
-	ids = landIDS
+func (g *Game) landIDS() AreaIDS {
 	if g.Version == 2 {
-		ids = append(ids, JawaTengah136, JawaTengah137)
+		return landIDS2
 	}
-	return ids
+	return landIDS
 }
 
 func (g *Game) isLandID(id AreaID) bool {
-	return g.landIDS().include(id)
+	if g.Version == 2 {
+		return landSet2.has(id)
+	}
+	return landSet.has(id)
 }
 
 func (g *Game) areaIDS() (ids AreaIDS) {
@@ -585,7 +566,7 @@ func (g *Game) areaIDS() (ids AreaIDS) {
 }
 
 func (g *Game) isSeaID(id AreaID) bool {
-	return seaIDS.include(id)
+	return seaSet.has(id)
 }
 
 func (g *Game) seaAreas() (as Areas) {
@@ -1179,32 +1160,40 @@ func (c *Company) ExpansionAreas() Areas {
 	if c.IsProductionCompany() {
 		return c.Zones.Areas().expandAreasFor(c)
 	}
-	var expansionAreas Areas
-	for _, area := range c.Zones.Areas() {
-		for _, a := range area.AdjacentSeaAreas() {
-			if !expansionAreas.include(a) {
-				expansionAreas = append(expansionAreas, a)
-			}
-		}
-	}
-	return expansionAreas
+	return c.g.areasIn(c.g.neighborhood(c.Zones.set()).intersect(seaSet))
 }
 
 //g.RequiredExpansions = min(cp.Technologies[ExpansionsTech], len(company.ExpansionAreas()))
 
 func (as Areas) expandAreasFor(c *Company) Areas {
 	var expansionAreas Areas
-	for _, area := range as {
-		for _, a := range area.AdjacentLandAreas() {
-			if !a.hasProducer() && !a.hasCity() && !a.adjacentAreaHasCompetingCompanyFor(c) &&
-				!expansionAreas.include(a) && !as.include(a) {
-				expansionAreas = append(expansionAreas, a)
-			}
+	own := as.set()
+	for _, a := range c.g.areasIn(c.g.neighborhood(own).minus(own).minus(seaSet)) {
+		if !a.hasProducer() && !a.hasCity() && !a.adjacentAreaHasCompetingCompanyFor(c) {
+			expansionAreas = append(expansionAreas, a)
 		}
 	}
 	return expansionAreas
 }
 
+func (as Areas) set() (s areaSet) {
+	for _, a := range as {
+		s.add(a.ID)
+	}
+	return
+}
+
+// areasIn returns the areas of s in increasing order of id.
+func (g *Game) areasIn(s areaSet) Areas {
+	var as Areas
+	for _, id := range s.ids() {
+		if a := g.GetArea(id); a != nil {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
 func (c *Company) requiredExpansions() int {
 	result := 0
 	maxExpansions := c.g.CurrentPlayer().Technologies[ExpansionsTech]
@@ -1225,7 +1214,6 @@ func (c *Company) requiredExpansions() int {
 		result, areas = l, append(areas, areas.expandAreasFor(c)...)
 		//		c.g.debugf("expansionAreas: %s", areas.ids())
 	}
-	return result
 }
 
 func (a *Area) hasProducer() bool {
@@ -1295,6 +1283,7 @@ func (g *Game) adminArea(c *gin.Context, cu *user.User) (string, game.ActionType
 		}
 	}
 
+	g.editedOutsideJournal()
 	return "", game.Save, err
 }
 
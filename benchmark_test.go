@@ -0,0 +1,95 @@
+package indonesia
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+const (
+	// benchmarkGames is the number of games from which benchmark positions are taken.
+	benchmarkGames = 4
+	// benchmarkEvery is the number of actions between benchmark positions.
+	benchmarkEvery = 25
+)
+
+var (
+	positionsOnce sync.Once
+	positions     []*Game
+	positionsErr  error
+)
+
+// benchmarkPositions returns every benchmarkEvery-th position of games played by heuristic bots.
+func benchmarkPositions(b *testing.B) []*Game {
+	b.Helper()
+
+	positionsOnce.Do(func() {
+		log.DefaultLevel = log.LvlNone
+		bot := HeuristicBot{}
+		for seed := int64(1); seed <= benchmarkGames; seed++ {
+			g, err := NewHeadless(seed, "A", "B", "C", "D")
+			if err != nil {
+				positionsErr = err
+				return
+			}
+			for step := 1; g.CurrentPlayer() != nil; step++ {
+				pid := g.CurrentPlayer().ID()
+				cmd := bot.Choose(g, pid)
+				if cmd == nil {
+					break
+				}
+				if _, err := g.Apply(pid, cmd); err != nil {
+					positionsErr = err
+					return
+				}
+				if step%benchmarkEvery == 0 {
+					position, err := g.Clone()
+					if err != nil {
+						positionsErr = err
+						return
+					}
+					positions = append(positions, position)
+				}
+			}
+		}
+	})
+	if positionsErr != nil {
+		b.Fatal(positionsErr)
+	}
+	return positions
+}
+
+// benchmarkPositionsWith runs f over the benchmark positions, one position per iteration.
+func benchmarkPositionsWith(b *testing.B, f func(*Game)) {
+	ps := benchmarkPositions(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f(ps[i%len(ps)])
+	}
+}
+
+func BenchmarkMaxDeliveries(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		for _, c := range g.Companies() {
+			c.MaxDeliveries()
+		}
+	})
+}
+
+func BenchmarkExpansionAreas(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		for _, c := range g.Companies() {
+			c.ExpansionAreas()
+		}
+	})
+}
+
+func BenchmarkLegalActions(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		if p := g.CurrentPlayer(); p != nil {
+			LegalActions(g, p.ID())
+		}
+	})
+}
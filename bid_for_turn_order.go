@@ -1,70 +1,70 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 	"sort"
-	"strconv"
 
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(bidEntry))
-	gob.Register(new(turnOrderEntry))
+	registerType(new(bidEntry))
+	registerType(new(turnOrderEntry))
 }
 
 const NoBid = -1
 
-func (g *Game) startBidForTurnOrder(c *gin.Context) *Player {
+func (g *Game) startBidForTurnOrder() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = BidForTurnOrder
-	return g.Players()[0]
+	if g.SealedBids {
+		g.startSealedBids()
+		return
+	}
+	g.setCurrentPlayers(g.Players()[0])
 }
 
-func (g *Game) placeTurnOrderBid(c *gin.Context, cu *user.User) (tmpl string, act game.ActionType, err error) {
+func (g *Game) placeTurnOrderBid(p *Player, bid int) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if err = g.validateBid(c, cu); err != nil {
-		tmpl, act = "indonesia/flash_notice", game.None
+	if err = g.validateBid(p, bid); err != nil {
+		tmpl = "indonesia/flash_notice"
 		return
 	}
 
-	cp := g.CurrentPlayer()
-	cp.Bank += cp.Bid
-	cp.Rupiah -= cp.Bid
-	cp.PerformedAction = true
+	if g.SealedBids {
+		return g.sealTurnOrderBid(p, bid)
+	}
+
+	p.Bid = bid
+	p.Bank += p.Bid
+	p.Rupiah -= p.Bid
+	p.PerformedAction = true
 
 	// Log placement
-	e := g.newBidEntryFor(cp)
-	restful.AddNoticef(c, string(e.HTML(c)))
-	tmpl, act = "indonesia/turn_order_bid_update", game.Cache
+	e := g.newBidEntryFor(p)
+	g.emit(e)
+	tmpl = "indonesia/turn_order_bid_update"
 	return
 }
 
-func (g *Game) validateBid(c *gin.Context, cu *user.User) (err error) {
+func (g *Game) validateBid(p *Player, bid int) (err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	switch err = g.validatePlayerAction(cu); {
+	switch err = g.validatePlayerAction(p); {
 	case err != nil:
-	default:
-		cp := g.CurrentPlayer()
-		switch cp.Bid, err = strconv.Atoi(c.PostForm("Bid")); {
-		case err != nil:
-		case cp.Bid > cp.Rupiah:
-			err = sn.NewVError("You bid more than you have.")
-		case cp.Bid < 0:
-			err = sn.NewVError("You can't bid less than zero.")
-		}
+	case bid > 0 && bid > p.Rupiah:
+		// A player in debt from expanding may still bid nothing.
+		err = sn.NewVError("You bid more than you have.")
+	case bid < 0:
+		err = sn.NewVError("You can't bid less than zero.")
 	}
 	return
 }
@@ -92,10 +92,14 @@ func (e *bidEntry) HTML(c *gin.Context) template.HTML {
 		g.NameByPID(e.PlayerID), e.Bid, e.BidMultiplier, e.Bid*e.BidMultiplier)
 }
 
-func (g *Game) setTurnOrder(c *gin.Context, cu *user.User) {
+func (g *Game) setTurnOrder() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
+	if g.SealedBids {
+		g.revealSealedBids()
+	}
+
 	com, n := make([]int, g.NumPlayers), make([]int, g.NumPlayers)
 	for i, p := range g.Players() {
 		com[i] = p.ID()
@@ -115,7 +119,7 @@ func (g *Game) setTurnOrder(c *gin.Context, cu *user.User) {
 		b[pid] = p.TotalBid()
 	}
 	g.newTurnOrderEntry(com, n, b)
-	g.startMergers(c, cu)
+	g.startMergers()
 }
 
 type turnOrderEntry struct {
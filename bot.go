@@ -0,0 +1,42 @@
+package indonesia
+
+// Bot chooses the actions of a player seat.
+// Bots only ever choose among LegalActions, so a chosen action may be applied
+// through Apply like that of any human player.
+type Bot interface {
+	// Choose returns the next action of the player having id pid,
+	// or nil if the player has no legal action.
+	Choose(g *Game, pid int) Command
+}
+
+// botActions returns the legal actions of the player having id pid that bots
+// choose among.  Turn order bids are limited to those listed by turnOrderBids,
+// since the bids between them only raise the price of the same place, and Siap
+// Faji removal sets to the best maxRemovalSets.
+func botActions(g *Game, pid int) []Command {
+	cmds := LegalActions(g, pid)
+
+	worth := make(map[int]bool)
+	if g.Phase == BidForTurnOrder {
+		for _, bid := range g.turnOrderBids(g.PlayerByID(pid)) {
+			worth[bid] = true
+		}
+	}
+
+	var pruned []Command
+	sets := 0
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case TurnOrderBid:
+			if !worth[cmd.Bid] {
+				continue
+			}
+		case RemoveRiceSpiceSet:
+			if sets++; sets > maxRemovalSets {
+				continue
+			}
+		}
+		pruned = append(pruned, cmd)
+	}
+	return pruned
+}
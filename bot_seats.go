@@ -0,0 +1,187 @@
+package indonesia
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/restful"
+	"github.com/SlothNinja/sn"
+	"github.com/SlothNinja/user"
+	"github.com/gin-gonic/gin"
+)
+
+// BotSeat records a seat played by a bot rather than a user.
+// Bot seats are given negative user ids, which no user has.
+type BotSeat struct {
+	UserID   int64
+	Strength Strength
+}
+
+const (
+	// maxBotActions caps the bot actions applied in one request, guarding against a bot that never finishes.
+	maxBotActions = 5000
+	// botThinkingTime caps the time bots may search in one request.  A search is
+	// cut short to the time left, and once it is spent the remaining bot decisions
+	// of the request are made by the HeuristicBot.
+	botThinkingTime = 20 * time.Second
+)
+
+// addBot fills the next open seat of a recruiting game with a bot of strength s.
+// It reports whether the game is now full and should be started.
+func (g *Game) addBot(s Strength) (bool, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if _, ok := strengthStrings[s]; !ok {
+		return false, sn.NewVError("Received invalid bot strength.")
+	}
+	if len(g.UserIDS) >= g.NumPlayers {
+		return false, sn.NewVError("Game already has the maximum number of players.")
+	}
+
+	id := int64(-1)
+	for _, seat := range g.BotSeats {
+		if seat.UserID <= id {
+			id = seat.UserID - 1
+		}
+	}
+
+	u := user.New(id)
+	u.Name = fmt.Sprintf("%s Bot %d", s, -id)
+	g.AddUser(u)
+	g.BotSeats = append(g.BotSeats, BotSeat{UserID: id, Strength: s})
+	return len(g.UserIDS) == g.NumPlayers, nil
+}
+
+// addBotsFrom fills seats with the number of bots given by the bots form value,
+// with the strength given by the bot-strength form value.
+// It reports whether the game is now full and should be started.
+func (g *Game) addBotsFrom(c *gin.Context) (bool, error) {
+	n, _ := strconv.Atoi(c.PostForm("bots"))
+	s, _ := strconv.Atoi(c.PostForm("bot-strength"))
+
+	var start bool
+	for i := 0; i < n; i++ {
+		var err error
+		if start, err = g.addBot(Strength(s)); err != nil {
+			return false, err
+		}
+	}
+	return start, nil
+}
+
+// acceptBot fills a seat with a bot of the strength given by the bot-strength form value.
+// Only the creator of the game may do so.
+func (g *Game) acceptBot(c *gin.Context, cu *user.User) (bool, error) {
+	if cu == nil || cu.ID() != g.CreatorID {
+		return false, sn.NewVError("Only the creator of the game may add a bot.")
+	}
+	if g.Status != game.Recruiting {
+		return false, sn.NewVError("Game is no longer recruiting.")
+	}
+
+	s, _ := strconv.Atoi(c.PostForm("bot-strength"))
+	return g.addBot(Strength(s))
+}
+
+func (g *Game) botSeatFor(p *Player) (BotSeat, bool) {
+	if p != nil {
+		id := g.UserIDFor(p)
+		for _, seat := range g.BotSeats {
+			if seat.UserID == id {
+				return seat, true
+			}
+		}
+	}
+	return BotSeat{}, false
+}
+
+// currentBot returns the first current player played by a bot.
+func (g *Game) currentBot() (*Player, BotSeat, bool) {
+	for _, p := range g.Players() {
+		if !g.isCurrentPlayer(p) {
+			continue
+		}
+		if seat, ok := g.botSeatFor(p); ok {
+			return p, seat, true
+		}
+	}
+	return nil, BotSeat{}, false
+}
+
+// IsBot reports whether p is played by a bot.
+func (g *Game) IsBot(p *Player) bool {
+	_, ok := g.botSeatFor(p)
+	return ok
+}
+
+// playBots applies the actions of bot seats for as long as a bot is the current player.
+func (g *Game) playBots() (Events, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	var es Events
+	deadline := time.Now().Add(botThinkingTime)
+	for i := 0; i < maxBotActions; i++ {
+		if g.gameOver() || len(g.CPUserIndices) == 0 {
+			return es, nil
+		}
+
+		p, seat, ok := g.currentBot()
+		if !ok {
+			return es, nil
+		}
+
+		var bot Bot = HeuristicBot{}
+		if left := time.Until(deadline); seat.Strength != Heuristic && left > 0 {
+			mcts := NewMCTSBot(seat.Strength, g.Seed+int64(len(g.Journal)))
+			if mcts.Duration > left {
+				mcts.Duration = left
+			}
+			bot = mcts
+		}
+
+		cmd := bot.Choose(g, p.ID())
+		if cmd == nil {
+			return es, fmt.Errorf("%s has no legal action", g.NameFor(p))
+		}
+		bes, err := g.Apply(p.ID(), cmd)
+		if err != nil {
+			return es, err
+		}
+		es = append(es, bes...)
+	}
+	return es, fmt.Errorf("bots did not finish their turns after %d actions", maxBotActions)
+}
+
+// playStoredBots plays the bots of the stored game having id in a step of its own,
+// once the action of cu is saved, so a failing bot never loses that action.  A bot
+// failure is logged and reported, and leaves the game waiting on the bot.  It returns
+// the game as the bots left it, or nil if no bot was to play or the bots failed.
+func (client *Client) playStoredBots(c *gin.Context, id int64, cu *user.User) *Game {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g, err := client.retrySave(c, id, cu, func(g *Game) (Events, bool, error) {
+		if _, _, ok := g.currentBot(); !ok {
+			return nil, false, nil
+		}
+		es, err := g.playBots()
+		return es, true, err
+	})
+	if err != nil {
+		client.Log.Errorf(err.Error())
+		restful.AddErrorf(c, "%v", err)
+		return nil
+	}
+
+	if g != nil && g.Status == game.Completed {
+		if err := g.SendEndGameNotifications(c); err != nil {
+			client.Log.Warningf(err.Error())
+		}
+	}
+	return g
+}
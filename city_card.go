@@ -2,9 +2,9 @@ package indonesia
 
 import (
 	"html/template"
+	"math/rand"
 
 	"github.com/SlothNinja/restful"
-	"github.com/SlothNinja/sn"
 )
 
 type CityCard struct {
@@ -48,14 +48,14 @@ func (c *CityCard) IDString() template.HTML {
 	return restful.HTML("%s-%d", c.Era, c.Type)
 }
 
-func (cs *CityCards) draw() *CityCard {
+func (cs *CityCards) draw(r *rand.Rand) *CityCard {
 	var card *CityCard
-	*cs, card = cs.drawS()
+	*cs, card = cs.drawS(r)
 	return card
 }
 
-func (cs CityCards) drawS() (CityCards, *CityCard) {
-	i := sn.MyRand.Intn(len(cs))
+func (cs CityCards) drawS(r *rand.Rand) (CityCards, *CityCard) {
+	i := r.Intn(len(cs))
 	card := cs[i]
 	cards := cs.removeAt(i)
 	return cards, card
@@ -80,11 +80,12 @@ func (g *Game) dealCityCards() {
 	a := newADeck()
 	b := newBDeck()
 	c := newCDeck()
+	r := g.rand()
 	for _, p := range g.Players() {
 		if len(g.Players()) == 2 {
-			p.CityCards = CityCards{a.draw(), a.draw(), b.draw(), b.draw(), c.draw(), c.draw()}
+			p.CityCards = CityCards{a.draw(r), a.draw(r), b.draw(r), b.draw(r), c.draw(r), c.draw(r)}
 		} else {
-			p.CityCards = CityCards{a.draw(), b.draw(), c.draw()}
+			p.CityCards = CityCards{a.draw(r), b.draw(r), c.draw(r)}
 		}
 	}
 }
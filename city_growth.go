@@ -1,16 +1,12 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"fmt"
 	"html/template"
 
-	"github.com/SlothNinja/contest"
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,17 +17,18 @@ const (
 )
 
 func init() {
-	gob.Register(new(cityGrowthEntry))
-	gob.Register(new(deliveredGoodsEntry))
+	registerType(new(cityGrowthEntry))
+	registerType(new(deliveredGoodsEntry))
 }
 
 type cityGrowthMap map[int]Cities
 
-func (client *Client) startCityGrowth(c *gin.Context, g *Game) ([]*contest.Contest, error) {
+func (g *Game) startCityGrowth() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = CityGrowth
+	g.beginningOfPhaseReset()
 	g.setCurrentPlayers(g.Players()[0])
 	g.newDeliveredGoodsEntry()
 	cmap := g.CityGrowthMap()
@@ -41,16 +38,14 @@ func (client *Client) startCityGrowth(c *gin.Context, g *Game) ([]*contest.Conte
 
 	switch {
 	case c3stonesToUse > 0 && c3stonesToUse < c3growth:
-		return nil, nil
 	case c2stonesToUse > 0 && c2stonesToUse < c2growth:
-		return nil, nil
 	default:
 		for _, cities := range cmap {
 			for _, city := range cities {
 				g.grow(city)
 			}
 		}
-		return client.startNewEra(c, g)
+		g.startNewEra()
 	}
 }
 
@@ -160,29 +155,47 @@ func (e *deliveredGoodsEntry) HTML(c *gin.Context) (s template.HTML) {
 	return s
 }
 
-func (g *Game) cityGrowth(c *gin.Context, cu *user.User) (tmpl string, act game.ActionType, err error) {
+func (g *Game) cityGrowth(cp *Player, ids AreaIDS) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	var cs Cities
 
-	if cs, err = g.validateCityGrowth(c, cu); err != nil {
-		tmpl, act = "indonesia/flash_notice", game.None
+	if cs, err = g.validateCityGrowth(cp, ids); err != nil {
+		tmpl = "indonesia/flash_notice"
 		return
 	}
 
 	for _, c := range cs {
 		g.grow(c)
 	}
-	g.CurrentPlayer().PerformedAction = true
-	tmpl, act = "indonesia/city_growth_update", game.Cache
+	cp.PerformedAction = true
+	tmpl = "indonesia/city_growth_update"
 	return
 }
 
-func (g *Game) validateCityGrowth(c *gin.Context, cu *user.User) (cs Cities, err error) {
+// cityGrowthSelection returns the areas of the cities checked in the city growth form.
+func (g *Game) cityGrowthSelection(c *gin.Context) AreaIDS {
+	var ids AreaIDS
+	for size, cities := range g.CityGrowthMap() {
+		for i, city := range cities {
+			key := fmt.Sprintf("%d-%d", size, i)
+			if v := c.PostForm(key); v == "on" {
+				ids = append(ids, city.a.ID)
+			}
+		}
+	}
+	return ids
+}
+
+func (g *Game) validateCityGrowth(cp *Player, ids AreaIDS) (cs Cities, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
+	if err = g.validatePlayerAction(cp); err != nil {
+		return
+	}
+
 	cmap := g.CityGrowthMap()
 	for size, cities := range cmap {
 		var count, stonesToUse int
@@ -192,9 +205,8 @@ func (g *Game) validateCityGrowth(c *gin.Context, cu *user.User) (cs Cities, err
 			count, stonesToUse = 0, g.C3StonesToUse(cmap)
 		}
 
-		for i, city := range cities {
-			key := fmt.Sprintf("%d-%d", size, i)
-			if v := c.PostForm(key); v == "on" {
+		for _, city := range cities {
+			if ids.include(city.a.ID) {
 				count += 1
 				cs = append(cs, city)
 			}
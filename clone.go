@@ -0,0 +1,53 @@
+package indonesia
+
+import (
+	"github.com/SlothNinja/codec"
+	"github.com/SlothNinja/log"
+)
+
+// Clone returns a deep copy of g, including the selections of the current turn,
+// that can be played forward in memory without affecting g.
+// The clone has no datastore key and no users beyond their ids and names.
+func (g *Game) Clone() (*Game, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	encoded, err := codec.Encode(g.State)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newState()
+	if err := codec.Decode(&s, encoded); err != nil {
+		return nil, err
+	}
+	if s.TempData == nil {
+		s.TempData = new(TempData)
+	}
+
+	c := New(nil, 0)
+	c.State = s
+	c.Title = g.Title
+	c.Turn, c.Round = g.Turn, g.Round
+	c.Phase, c.SubPhase = g.Phase, g.SubPhase
+	c.Status = g.Status
+	c.NumPlayers = g.NumPlayers
+	c.UserIDS = append([]int64(nil), g.UserIDS...)
+	c.UserNames = append([]string(nil), g.UserNames...)
+	c.OrderIDS = append(c.OrderIDS, g.OrderIDS...)
+	c.CPUserIndices = append(c.CPUserIndices, g.CPUserIndices...)
+	c.WinnerIDS = append(c.WinnerIDS, g.WinnerIDS...)
+
+	for _, p := range c.Players() {
+		p.Init(c)
+	}
+	c.initAreas()
+	if c.Merger != nil {
+		c.Merger.g = c
+	}
+	if c.SiapFajiMerger != nil {
+		c.SiapFajiMerger.init(c)
+		c.SiapFajiMerger.removals = g.SiapFajiMerger.removals
+	}
+	return c, nil
+}
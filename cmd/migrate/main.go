@@ -0,0 +1,82 @@
+// Command migrate upgrades every stored game of Indonesia to the current
+// schema version.  Games are read from Cloud Datastore, or from the emulator
+// named by DATASTORE_EMULATOR_HOST, unless a file store directory is given.
+// Saving an upgraded game updates it, so turns cached by players in progress
+// must be redone.
+//
+// Usage:
+//
+//	migrate [-project id | -dir path] [-n]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/indonesia"
+	"github.com/SlothNinja/log"
+)
+
+var (
+	project = flag.String("project", os.Getenv("DATASTORE_PROJECT_ID"), "Cloud Datastore project holding the games")
+	dir     = flag.String("dir", "", "directory of a file store holding the games, instead of Cloud Datastore")
+	dryRun  = flag.Bool("n", false, "report the games to upgrade without saving them")
+)
+
+func main() {
+	flag.Parse()
+	log.DefaultLevel = log.LvlNone
+
+	c := context.Background()
+	s, err := open(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ks, err := indonesia.GameKeys(c, s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var upgraded, failed int
+	for _, k := range ks {
+		from, err := indonesia.MigrateGame(c, s, k, *dryRun)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "game %d: %v\n", k.ID, err)
+			failed++
+		case from != indonesia.CurrentSchemaVersion:
+			fmt.Printf("game %d: schema version %d to %d\n", k.ID, from, indonesia.CurrentSchemaVersion)
+			upgraded++
+		}
+	}
+
+	verb := "upgraded"
+	if *dryRun {
+		verb = "to upgrade"
+	}
+	fmt.Printf("%d of %d games %s, %d failed\n", upgraded, len(ks), verb, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func open(c context.Context) (indonesia.GameStore, error) {
+	if *dir != "" {
+		return indonesia.OpenFileStore(*dir)
+	}
+	if *project == "" {
+		return nil, fmt.Errorf("either -project or -dir is required")
+	}
+
+	dc, err := datastore.NewClient(c, *project)
+	if err != nil {
+		return nil, err
+	}
+	return indonesia.NewDatastoreStore(dc), nil
+}
@@ -0,0 +1,92 @@
+// Command record exports stored games of Indonesia as game records, and
+// imports game records as new stored games.  Games are read from Cloud
+// Datastore, or from the emulator named by DATASTORE_EMULATOR_HOST, unless a
+// file store directory is given.
+//
+// Usage:
+//
+//	record [-project id | -dir path] -game id         print the record of a game
+//	record [-project id | -dir path] -import file     store the game of a record
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/indonesia"
+	"github.com/SlothNinja/log"
+)
+
+var (
+	project = flag.String("project", os.Getenv("DATASTORE_PROJECT_ID"), "Cloud Datastore project holding the games")
+	dir     = flag.String("dir", "", "directory of a file store holding the games, instead of Cloud Datastore")
+	id      = flag.Int64("game", 0, "id of the game to export")
+	imp     = flag.String("import", "", "file holding the record to import, or - for standard input")
+)
+
+func main() {
+	flag.Parse()
+	log.DefaultLevel = log.LvlNone
+
+	c := context.Background()
+	s, err := open(c)
+	if err != nil {
+		fail(2, err)
+	}
+
+	switch {
+	case *imp != "":
+		r, err := input(*imp)
+		if err != nil {
+			fail(1, err)
+		}
+		defer r.Close()
+
+		k, err := indonesia.ImportGame(c, s, r)
+		if err != nil {
+			fail(1, err)
+		}
+		fmt.Println(k.ID)
+	case *id != 0:
+		g, err := indonesia.LoadGame(c, s, indonesia.GameKey(*id))
+		if err != nil {
+			fail(1, err)
+		}
+		if err := g.Export(os.Stdout); err != nil {
+			fail(1, err)
+		}
+	default:
+		fail(2, fmt.Errorf("either -game or -import is required"))
+	}
+}
+
+func input(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(name)
+}
+
+func fail(code int, err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(code)
+}
+
+func open(c context.Context) (indonesia.GameStore, error) {
+	if *dir != "" {
+		return indonesia.OpenFileStore(*dir)
+	}
+	if *project == "" {
+		return nil, fmt.Errorf("either -project or -dir is required")
+	}
+
+	dc, err := datastore.NewClient(c, *project)
+	if err != nil {
+		return nil, err
+	}
+	return indonesia.NewDatastoreStore(dc), nil
+}
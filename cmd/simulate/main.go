@@ -0,0 +1,201 @@
+// Command simulate plays complete games of Indonesia in memory between
+// simulated players and reports the results.  Unless played by a bot, a
+// simulated player picks uniformly among its legal actions, so the tool is
+// suited to soak testing rule changes across many random games.
+//
+// Usage:
+//
+//	simulate [-players n] [-mcts n] [-strength s] [-heuristic n] [-games n] [-seed s] [-max-steps n] [-sealed] [-log] [-record] [-verify]
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/indonesia"
+	"github.com/SlothNinja/log"
+)
+
+var (
+	numPlayers = flag.Int("players", 4, "number of simulated players (2 to 5)")
+	numSearch  = flag.Int("mcts", 0, "number of players played by the search bot")
+	strength   = flag.Int("strength", int(indonesia.Medium), "strength of the search bot, from 1 (easy) to 4 (expert)")
+	numBots    = flag.Int("heuristic", 0, "number of players played by the heuristic bot")
+	numGames   = flag.Int("games", 1, "number of games to play")
+	firstSeed  = flag.Int64("seed", 1, "seed of the first game; later games use the following seeds")
+	maxSteps   = flag.Int("max-steps", 50000, "number of actions after which a game is abandoned")
+	sealed     = flag.Bool("sealed", false, "bid for turn order with sealed bids")
+	showLog    = flag.Bool("log", false, "print the game log of each game")
+	showRecord = flag.Bool("record", false, "print the game record of each game")
+	verify     = flag.Bool("verify", true, "check that each game record replays to the same standings")
+)
+
+func main() {
+	flag.Parse()
+	log.DefaultLevel = log.LvlNone
+
+	var stalled, failed int
+	for i := 0; i < *numGames; i++ {
+		seed := *firstSeed + int64(i)
+		switch err := simulate(seed); {
+		case errors.Is(err, errStalled):
+			fmt.Fprintf(os.Stderr, "game %d: %v\n", seed, err)
+			stalled++
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "game %d: %v\n", seed, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("%d of %d games completed, %d stalled, %d failed\n", *numGames-stalled-failed, *numGames, stalled, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// errStalled reports a game abandoned after max-steps actions.  Random players
+// can reach positions in which nobody is able to acquire the remaining deeds.
+var errStalled = errors.New("stalled")
+
+func simulate(seed int64) error {
+	names := make([]string, *numPlayers)
+	bots := make([]indonesia.Bot, *numPlayers)
+	r := rand.New(rand.NewSource(seed))
+	for i := range names {
+		switch {
+		case i < *numSearch:
+			names[i] = fmt.Sprintf("MCTS %d", i+1)
+			bots[i] = indonesia.NewMCTSBot(indonesia.Strength(*strength), seed)
+		case i < *numSearch+*numBots:
+			names[i], bots[i] = fmt.Sprintf("Heuristic %d", i+1), indonesia.HeuristicBot{}
+		default:
+			names[i], bots[i] = fmt.Sprintf("Random %d", i+1), randomBot{r}
+		}
+	}
+
+	g, err := indonesia.NewHeadless(seed, names...)
+	if err != nil {
+		return err
+	}
+	g.SealedBids = *sealed
+
+	steps := 0
+	for ; g.Status != game.Completed; steps++ {
+		if steps == *maxSteps {
+			return fmt.Errorf("%w after %d actions in %s", errStalled, steps, phaseOf(g))
+		}
+		if len(g.CPUserIndices) == 0 {
+			return dump(g, fmt.Errorf("no current player in %s", phaseOf(g)))
+		}
+
+		pid := g.CPUserIndices[0]
+		cmd := bots[pid].Choose(g, pid)
+		if cmd == nil {
+			return dump(g, fmt.Errorf("no legal action for player %d in %s", pid, phaseOf(g)))
+		}
+		if _, err := g.Apply(pid, cmd); err != nil {
+			return dump(g, fmt.Errorf("legal action %T%+v failed: %v", cmd, cmd, err))
+		}
+	}
+
+	fmt.Printf("Game %d: %d players, %d rounds, %d actions\n", seed, len(names), g.Turn, steps)
+	for i, p := range g.Players() {
+		fmt.Printf("  %d. %-10s Rp %d\n", i+1, g.NameFor(p), p.Score())
+	}
+
+	if *showLog {
+		if err := g.WriteLog(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if *showRecord {
+		if err := g.Export(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if *verify {
+		return check(g)
+	}
+	return nil
+}
+
+// randomBot picks a random legal action.  The proposed flow is always accepted
+// when offered, since random ship and city selections rarely complete a delivery,
+// and passing is rare, since games in which everyone keeps passing never end.
+type randomBot struct {
+	r *rand.Rand
+}
+
+func (b randomBot) Choose(g *indonesia.Game, pid int) indonesia.Command {
+	cmds := indonesia.LegalActions(g, pid)
+	r := b.r
+	var others []indonesia.Command
+	for _, cmd := range cmds {
+		switch cmd := cmd.(type) {
+		case indonesia.AcceptProposedFlow:
+			return cmd
+		case indonesia.Pass, indonesia.StopExpanding:
+		case indonesia.MergerBid:
+			if cmd.Bid != indonesia.NoBid {
+				others = append(others, cmd)
+			}
+		default:
+			others = append(others, cmd)
+		}
+	}
+
+	switch {
+	case len(cmds) == 0:
+		return nil
+	case len(others) == 0 || r.Intn(10) == 0:
+		return cmds[r.Intn(len(cmds))]
+	default:
+		return others[r.Intn(len(others))]
+	}
+}
+
+// check exports g, imports the record, and compares the resulting standings.
+func check(g *indonesia.Game) error {
+	var buf bytes.Buffer
+	if err := g.Export(&buf); err != nil {
+		return err
+	}
+
+	replayed, err := indonesia.Import(&buf)
+	if err != nil {
+		return fmt.Errorf("replaying record: %v", err)
+	}
+
+	want, got := standings(g), standings(replayed)
+	if want != got {
+		return fmt.Errorf("replayed standings %s differ from %s", got, want)
+	}
+	return nil
+}
+
+func standings(g *indonesia.Game) string {
+	var s string
+	for _, p := range g.Players() {
+		s += fmt.Sprintf("[%s Rp %d]", g.NameFor(p), p.Score())
+	}
+	return s
+}
+
+func phaseOf(g *indonesia.Game) string {
+	if g.SubPhase != indonesia.NoSubPhase {
+		return fmt.Sprintf("%s (%s), round %d", g.PhaseName(), g.SubPhaseName(), g.Turn)
+	}
+	return fmt.Sprintf("%s, round %d", g.PhaseName(), g.Turn)
+}
+
+// dump prints the record of a failed game so that it can be imported and inspected.
+func dump(g *indonesia.Game, err error) error {
+	fmt.Fprintf(os.Stderr, "record of failed game %d:\n", g.Seed)
+	g.Export(os.Stderr)
+	return err
+}
@@ -0,0 +1,97 @@
+// Command state prints and replaces the saved state of stored games of
+// Indonesia in JSON, so games can be inspected, compared and repaired by hand,
+// and rebuilds the saved state of a game from its journal.
+// Games are read from Cloud Datastore, or from the emulator named by
+// DATASTORE_EMULATOR_HOST, unless a file store directory is given.
+//
+// Usage:
+//
+//	state [-project id | -dir path] -game id            print the state of a game
+//	state [-project id | -dir path] -game id -put file  replace the state of a game
+//	state [-project id | -dir path] -game id -rebuild   rebuild the state of a game from its journal
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/indonesia"
+	"github.com/SlothNinja/log"
+)
+
+var (
+	project = flag.String("project", os.Getenv("DATASTORE_PROJECT_ID"), "Cloud Datastore project holding the games")
+	dir     = flag.String("dir", "", "directory of a file store holding the games, instead of Cloud Datastore")
+	id      = flag.Int64("game", 0, "id of the game")
+	put     = flag.String("put", "", "file holding the replacement state, or - for standard input")
+	rebuild = flag.Bool("rebuild", false, "rebuild the state of the game by replaying its journal")
+)
+
+func main() {
+	flag.Parse()
+	log.DefaultLevel = log.LvlNone
+
+	c := context.Background()
+	s, err := open(c)
+	if err != nil {
+		fail(2, err)
+	}
+
+	switch {
+	case *id == 0:
+		fail(2, fmt.Errorf("-game is required"))
+	case *rebuild:
+		if err := indonesia.RebuildGame(c, s, indonesia.GameKey(*id)); err != nil {
+			fail(1, err)
+		}
+	case *put != "":
+		data, err := read(*put)
+		if err != nil {
+			fail(1, err)
+		}
+		if err := indonesia.PutStateJSON(c, s, indonesia.GameKey(*id), data); err != nil {
+			fail(1, err)
+		}
+	default:
+		g, err := indonesia.LoadGame(c, s, indonesia.GameKey(*id))
+		if err != nil {
+			fail(1, err)
+		}
+		data, err := g.StateJSON()
+		if err != nil {
+			fail(1, err)
+		}
+		os.Stdout.Write(data)
+	}
+}
+
+func read(name string) ([]byte, error) {
+	if name == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(name)
+}
+
+func fail(code int, err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(code)
+}
+
+func open(c context.Context) (indonesia.GameStore, error) {
+	if *dir != "" {
+		return indonesia.OpenFileStore(*dir)
+	}
+	if *project == "" {
+		return nil, fmt.Errorf("either -project or -dir is required")
+	}
+
+	dc, err := datastore.NewClient(c, *project)
+	if err != nil {
+		return nil, err
+	}
+	return indonesia.NewDatastoreStore(dc), nil
+}
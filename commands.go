@@ -0,0 +1,540 @@
+package indonesia
+
+import (
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/restful"
+	"github.com/SlothNinja/sn"
+	"github.com/SlothNinja/user"
+	"github.com/gin-gonic/gin"
+)
+
+// Command is a player action understood by the rules engine.
+// Commands are plain values, so they can be built by the web handlers,
+// a simulator, or a bot without an HTTP request.
+//
+// validate records the selections of the command in TempData and
+// reports whether the player may perform it.  apply performs a validated command.
+type Command interface {
+	validate(*Game, *Player) error
+	apply(*Game, *Player) (string, error)
+}
+
+// Events lists the game log entries announced while applying a command.
+type Events []Entryer
+
+func (g *Game) emit(e Entryer) {
+	g.events = append(g.events, e)
+}
+
+// Apply validates cmd and, if valid, applies it on behalf of the player having id pid.
+// It returns the log entries announced by the command.
+// If an error is returned, the game may be partially updated and should be discarded,
+// as the web handlers do.
+func (g *Game) Apply(pid int, cmd Command) (Events, error) {
+	_, es, err := g.apply(pid, cmd)
+	return es, err
+}
+
+func (g *Game) apply(pid int, cmd Command) (string, Events, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	p := g.PlayerByID(pid)
+	if !g.isCurrentPlayer(p) {
+		return "indonesia/flash_notice", nil, sn.NewVError("Only the current player can perform an action.")
+	}
+
+	for _, p := range g.Players() {
+		p.resetCache()
+	}
+
+	if err := cmd.validate(g, p); err != nil {
+		return "indonesia/flash_notice", nil, err
+	}
+
+	g.events = nil
+	tmpl, err := cmd.apply(g, p)
+	es := g.events
+	g.events = nil
+	if err != nil {
+		return "indonesia/flash_notice", nil, err
+	}
+	g.record(pid, cmd)
+	return tmpl, es, nil
+}
+
+// playerIDFor returns the id of the player seated for user u, or NoPlayerID if u is not seated.
+func (g *Game) playerIDFor(u *user.User) int {
+	if u == nil {
+		return NoPlayerID
+	}
+	for pid, uid := range g.UserIDS {
+		if uid == u.ID() {
+			return pid
+		}
+	}
+	return NoPlayerID
+}
+
+// allows reports whether the player having id pid may apply cmd.
+// Unlike Apply, it leaves the game untouched.
+func (g *Game) allows(pid int, cmd Command) bool {
+	p := g.PlayerByID(pid)
+	if !g.isCurrentPlayer(p) {
+		return false
+	}
+
+	saved := *g.TempData
+	defer func() { *g.TempData = saved }()
+	return cmd.validate(g, p) == nil
+}
+
+func addNotices(c *gin.Context, es Events) {
+	for _, e := range es {
+		restful.AddNoticef(c, string(e.HTML(c)))
+	}
+}
+
+// PlaceCity places a city in Area using the matching city card of the current era.
+type PlaceCity struct {
+	Area AreaID
+}
+
+func (cmd PlaceCity) validate(g *Game, p *Player) error {
+	if !p.CanPlaceCity() {
+		return sn.NewVError("You can not place a city now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, _, err := g.validatePlaceCity(p)
+	return err
+}
+
+func (cmd PlaceCity) apply(g *Game, p *Player) (string, error) {
+	return g.placeCity(p)
+}
+
+// PlayCard selects which city card places the city, when either card could be used.
+type PlayCard struct {
+	Card int
+}
+
+func (cmd PlayCard) validate(g *Game, p *Player) error {
+	if !p.CanSelectCard() {
+		return sn.NewVError("You can not select a city card now.")
+	}
+	g.SelectedCardIndex = cmd.Card
+	_, err := g.validatePlayCard(p)
+	return err
+}
+
+func (cmd PlayCard) apply(g *Game, p *Player) (string, error) {
+	return g.playCard(p)
+}
+
+// TurnOrderBid bids rupiah for turn order.
+type TurnOrderBid struct {
+	Bid int
+}
+
+func (cmd TurnOrderBid) validate(g *Game, p *Player) error {
+	if !p.CanBid() {
+		return sn.NewVError("You can not bid for turn order now.")
+	}
+	return g.validateBid(p, cmd.Bid)
+}
+
+func (cmd TurnOrderBid) apply(g *Game, p *Player) (string, error) {
+	return g.placeTurnOrderBid(p, cmd.Bid)
+}
+
+// AnnounceMerger selects the first company of a merger.
+type AnnounceMerger struct {
+	OwnerID int
+	Slot    int
+}
+
+func (cmd AnnounceMerger) validate(g *Game, p *Player) error {
+	if !p.CanAnnounceMerger() {
+		return sn.NewVError("You can not announce a merger now.")
+	}
+	g.SelectedPlayerID, g.SelectedSlot = cmd.OwnerID, cmd.Slot
+	if com := g.SelectedCompany(); com != nil && !p.canSelectFirstCompany(com) {
+		return sn.NewVError("You can not merge the selected company.")
+	}
+	_, err := g.validateSelectCompany1(p)
+	return err
+}
+
+func (cmd AnnounceMerger) apply(g *Game, p *Player) (string, error) {
+	return g.selectCompany1(p)
+}
+
+// AnnounceMergerPartner selects the second company of an announced merger.
+type AnnounceMergerPartner struct {
+	OwnerID int
+	Slot    int
+}
+
+func (cmd AnnounceMergerPartner) validate(g *Game, p *Player) error {
+	if !p.CanAnnounceSecondCompany() {
+		return sn.NewVError("You can not select a second merger company now.")
+	}
+	g.SelectedPlayerID, g.SelectedSlot = cmd.OwnerID, cmd.Slot
+	if com := g.SelectedCompany(); com != nil && !p.canSelectSecondCompany(com) {
+		return sn.NewVError("You can not merge the selected company with the announced company.")
+	}
+	_, err := g.validateSelectCompany2(p)
+	return err
+}
+
+func (cmd AnnounceMergerPartner) apply(g *Game, p *Player) (string, error) {
+	return g.selectCompany2(p)
+}
+
+// MergerBid bids for the announced merger.  A Bid of NoBid passes.
+type MergerBid struct {
+	Bid int
+}
+
+func (cmd MergerBid) validate(g *Game, p *Player) error {
+	if g.Phase != Mergers || g.SubPhase != MBid || g.Merger == nil {
+		return sn.NewVError("You can not bid on a merger now.")
+	}
+	return g.validateMergerBid(p, cmd.Bid)
+}
+
+func (cmd MergerBid) apply(g *Game, p *Player) (string, error) {
+	return g.mergerBid(p, cmd.Bid)
+}
+
+// RemoveRiceSpice removes the rice or spice in Area while forming a Siap Faji company.
+type RemoveRiceSpice struct {
+	Area AreaID
+}
+
+func (cmd RemoveRiceSpice) validate(g *Game, p *Player) error {
+	if !p.CanCreateSiapFaji() {
+		return sn.NewVError("You can not remove rice or spice now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, err := g.validateRemoveRiceSpice(p)
+	return err
+}
+
+func (cmd RemoveRiceSpice) apply(g *Game, p *Player) (string, error) {
+	return g.removeRiceSpice(p)
+}
+
+// RemoveRiceSpiceSet removes the rice or spice in each of Areas, completing a Siap Faji company.
+type RemoveRiceSpiceSet struct {
+	Areas AreaIDS
+}
+
+func (cmd RemoveRiceSpiceSet) validate(g *Game, p *Player) error {
+	if !p.CanCreateSiapFaji() {
+		return sn.NewVError("You can not remove rice or spice now.")
+	}
+	return g.validateRemoveRiceSpiceSet(p, cmd.Areas)
+}
+
+func (cmd RemoveRiceSpiceSet) apply(g *Game, p *Player) (string, error) {
+	return g.removeRiceSpiceSet(p, cmd.Areas)
+}
+
+// AcquireCompany acquires the deed at index Deed of the available deeds.
+type AcquireCompany struct {
+	Deed int
+}
+
+func (cmd AcquireCompany) validate(g *Game, p *Player) error {
+	if !p.CanAcquireCompany() {
+		return sn.NewVError("You can not acquire a company now.")
+	}
+	g.SelectedDeedIndex = cmd.Deed
+	_, _, _, _, err := g.validateAcquireCompany(p)
+	return err
+}
+
+func (cmd AcquireCompany) apply(g *Game, p *Player) (string, error) {
+	return g.acquireCompany(p)
+}
+
+// PlaceInitialProduct places the first goods of a newly acquired production company in Area.
+type PlaceInitialProduct struct {
+	Area AreaID
+}
+
+func (cmd PlaceInitialProduct) validate(g *Game, p *Player) error {
+	if !p.canPlaceInitialProduct() {
+		return sn.NewVError("You can not place an initial product now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, err := g.validateplaceInitialProduct(p)
+	return err
+}
+
+func (cmd PlaceInitialProduct) apply(g *Game, p *Player) (string, error) {
+	return g.placeInitialProduct(p)
+}
+
+// PlaceInitialShip places the first ship of a newly acquired shipping company in Area.
+type PlaceInitialShip struct {
+	Area AreaID
+}
+
+func (cmd PlaceInitialShip) validate(g *Game, p *Player) error {
+	if !p.canPlaceInitialShip() {
+		return sn.NewVError("You can not place an initial ship now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, err := g.validateplaceInitialShip(p)
+	return err
+}
+
+func (cmd PlaceInitialShip) apply(g *Game, p *Player) (string, error) {
+	return g.placeInitialShip(p)
+}
+
+// ConductResearch advances Technology.
+type ConductResearch struct {
+	Technology Technology
+}
+
+func (cmd ConductResearch) validate(g *Game, p *Player) error {
+	if !p.CanResearch() {
+		return sn.NewVError("You can not research now.")
+	}
+	g.SelectedTechnology = cmd.Technology
+	_, err := g.validateConductResearch(p)
+	return err
+}
+
+func (cmd ConductResearch) apply(g *Game, p *Player) (string, error) {
+	return g.conductResearch(p)
+}
+
+// SelectHullPlayer selects the player whose hull size increases after researching hull.
+type SelectHullPlayer struct {
+	PlayerID int
+}
+
+func (cmd SelectHullPlayer) validate(g *Game, p *Player) error {
+	if g.Phase != Research || g.SubPhase != RSelectPlayer {
+		return sn.NewVError("You can not select a player now.")
+	}
+	g.SelectedPlayerID = cmd.PlayerID
+	_, err := g.validateSelectHullPlayer(p)
+	return err
+}
+
+func (cmd SelectHullPlayer) apply(g *Game, p *Player) (string, error) {
+	return g.selectHullPlayer(p)
+}
+
+// OperateCompany selects the company in Slot of the current player to operate.
+type OperateCompany struct {
+	Slot int
+}
+
+func (cmd OperateCompany) validate(g *Game, p *Player) error {
+	if !p.CanSelectCompanyToOperate() {
+		return sn.NewVError("You can not select a company to operate now.")
+	}
+	g.setSelectedPlayer(p)
+	g.SelectedSlot = cmd.Slot
+	if com := g.SelectedCompany(); com != nil && !p.CanSelectCompany(com) {
+		return sn.NewVError("The selected company has already operated.")
+	}
+	_, err := g.validateSelectCompany(p)
+	return err
+}
+
+func (cmd OperateCompany) apply(g *Game, p *Player) (string, error) {
+	return g.selectCompany(p)
+}
+
+// AcceptProposedFlow delivers goods along the proposed delivery plan.
+type AcceptProposedFlow struct{}
+
+func (cmd AcceptProposedFlow) validate(g *Game, p *Player) error {
+	if !p.CanSelectGood() {
+		return sn.NewVError("You can not accept proposed deliveries now.")
+	}
+	_, err := g.validateAcceptProposedFlow(p)
+	return err
+}
+
+func (cmd AcceptProposedFlow) apply(g *Game, p *Player) (string, error) {
+	return g.acceptProposedFlow(p)
+}
+
+// AcceptAlternativeFlow delivers goods along the alternative delivery plan having index Plan.
+type AcceptAlternativeFlow struct {
+	Plan int
+}
+
+func (cmd AcceptAlternativeFlow) validate(g *Game, p *Player) error {
+	if !p.CanSelectGood() {
+		return sn.NewVError("You can not accept alternative deliveries now.")
+	}
+	_, err := g.validateAcceptAlternativeFlow(p, cmd.Plan)
+	return err
+}
+
+func (cmd AcceptAlternativeFlow) apply(g *Game, p *Player) (string, error) {
+	return g.acceptAlternativeFlow(p, cmd.Plan)
+}
+
+// SelectGood selects the goods in Area for delivery.
+type SelectGood struct {
+	Area AreaID
+}
+
+func (cmd SelectGood) validate(g *Game, p *Player) error {
+	if !p.CanSelectGood() {
+		return sn.NewVError("You can not select goods now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, err := g.validateSelectGood(p)
+	return err
+}
+
+func (cmd SelectGood) apply(g *Game, p *Player) (string, error) {
+	return g.selectGood(p)
+}
+
+// SelectShip selects the ship at index Shipper of sea Area to carry the goods being delivered.
+type SelectShip struct {
+	Area    AreaID
+	Shipper int
+}
+
+func (cmd SelectShip) validate(g *Game, p *Player) error {
+	if !p.CanSelectShip() && !p.CanSelectCityOrShip() {
+		return sn.NewVError("You can not select a ship now.")
+	}
+	if a := g.GetArea(cmd.Area); a == nil || cmd.Shipper < 0 || cmd.Shipper >= len(a.Shippers) {
+		return sn.NewVError("You must select a valid ship adjacent to the previously selected area.")
+	}
+	g.SelectedArea2ID, g.SelectedShipperIndex = cmd.Area, cmd.Shipper
+	_, _, _, _, err := g.validateSelectShip(p)
+	return err
+}
+
+func (cmd SelectShip) apply(g *Game, p *Player) (string, error) {
+	return g.selectShip(p)
+}
+
+// SelectCity delivers the goods being delivered to the city in Area.
+type SelectCity struct {
+	Area AreaID
+}
+
+func (cmd SelectCity) validate(g *Game, p *Player) error {
+	if !p.CanSelectCityOrShip() {
+		return sn.NewVError("You can not select a city now.")
+	}
+	g.SelectedArea2ID = cmd.Area
+	_, _, _, _, _, _, err := g.validateSelectCity(p)
+	return err
+}
+
+func (cmd SelectCity) apply(g *Game, p *Player) (string, error) {
+	return g.selectCity(p)
+}
+
+// ExpandProduction expands the operated production company into Area.
+type ExpandProduction struct {
+	Area AreaID
+}
+
+func (cmd ExpandProduction) validate(g *Game, p *Player) error {
+	if !p.CanExpandProduction() {
+		return sn.NewVError("You can not expand production now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, err := g.validateExpandProduction(p)
+	return err
+}
+
+func (cmd ExpandProduction) apply(g *Game, p *Player) (string, error) {
+	return g.expandProduction(p)
+}
+
+// ExpandShipping expands the operated shipping company into Area.
+type ExpandShipping struct {
+	Area AreaID
+}
+
+func (cmd ExpandShipping) validate(g *Game, p *Player) error {
+	if !p.canExpandShipping() {
+		return sn.NewVError("You can not expand shipping now.")
+	}
+	g.SelectedAreaID = cmd.Area
+	_, _, err := g.validateExpandShipping(p)
+	return err
+}
+
+func (cmd ExpandShipping) apply(g *Game, p *Player) (string, error) {
+	return g.expandShipping(p)
+}
+
+// StopExpanding ends the expansion of the operated company.
+type StopExpanding struct{}
+
+func (cmd StopExpanding) validate(g *Game, p *Player) error {
+	if g.Phase != Operations || (g.SubPhase != OPFreeExpansion && g.SubPhase != OPExpansion) {
+		return sn.NewVError("You can not stop expanding now.")
+	}
+	_, err := g.validateStopExpanding(p)
+	return err
+}
+
+func (cmd StopExpanding) apply(g *Game, p *Player) (string, error) {
+	return g.stopExpanding(p)
+}
+
+// GrowCities selects the cities in Areas that grow when city stones are short.
+type GrowCities struct {
+	Areas AreaIDS
+}
+
+func (cmd GrowCities) validate(g *Game, p *Player) error {
+	if g.Phase != CityGrowth {
+		return sn.NewVError("You can not grow cities now.")
+	}
+	_, err := g.validateCityGrowth(p, cmd.Areas)
+	return err
+}
+
+func (cmd GrowCities) apply(g *Game, p *Player) (string, error) {
+	return g.cityGrowth(p, cmd.Areas)
+}
+
+// Pass passes during the mergers or acquisitions phase.
+type Pass struct{}
+
+func (cmd Pass) validate(g *Game, p *Player) error {
+	return g.validatePass(p)
+}
+
+func (cmd Pass) apply(g *Game, p *Player) (string, error) {
+	return g.pass(p)
+}
+
+// FinishTurn ends the turn of the current player and advances the game.
+type FinishTurn struct{}
+
+func (cmd FinishTurn) validate(g *Game, p *Player) error {
+	return g.validateFinishTurnFor(p)
+}
+
+func (cmd FinishTurn) apply(g *Game, p *Player) (string, error) {
+	if err := g.finishTurn(p); err != nil {
+		return "", err
+	}
+
+	// Selections last a single turn, as they do when the game is saved and reloaded.
+	g.TempData = new(TempData)
+	return "", nil
+}
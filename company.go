@@ -14,6 +14,8 @@ type Company struct {
 	ShipType ShipType
 	Operated bool
 	Zones    Zones
+
+	forest *zoneForest
 }
 
 func (c *Company) Equal(company *Company) bool {
@@ -138,20 +140,11 @@ func (c *Company) AddShipIn(a *Area) {
 }
 
 func (c *Company) AddArea(a *Area) {
-	c.Zones = c.Zones.addZones(newZone(c.g, AreaIDS{a.ID}))
+	c.zoneForest().add(a.ID)
 }
 
 func (c *Company) RemoveArea(a *Area) {
-	if c.Areas().include(a) {
-		for _, zone := range c.Zones {
-			for _, area := range zone.Areas() {
-				if area.AdjacentAreas().include(a) {
-					zone.AreaIDS = zone.AreaIDS.remove(a.ID)
-					return
-				}
-			}
-		}
-	}
+	c.zoneForest().remove(a.ID)
 }
 
 func (c *Company) Areas() Areas {
@@ -177,12 +170,7 @@ func (c *Company) ZoneFor(a *Area) *Zone {
 	if c == nil || a == nil {
 		return nil
 	}
-	for _, zone := range c.Zones {
-		if zone.AreaIDS.include(a.ID) {
-			return zone
-		}
-	}
-	return nil
+	return c.zoneForest().zoneFor(a.ID)
 }
 
 var noAcquiredCompanyIndex = -1
@@ -333,19 +321,18 @@ func (ss Shippers) haveCapacity() bool {
 }
 
 func (c *Company) remove(a *Area) {
-	var zones Zones
-	for _, zone := range c.Zones {
-		a.Producer = nil
-		zone.AreaIDS = zone.AreaIDS.remove(a.ID)
-		if len(zone.AreaIDS) > 0 {
-			zones = append(zones, zone)
-		}
-	}
-	c.Zones = zones
+	a.Producer = nil
+	c.RemoveArea(a)
+}
+
+// regroup merges the adjacent zones of the company producing the same goods.
+func (c *Company) regroup() {
+	c.zoneForest().regroup()
 }
 
 func (c *Company) removeZoneAt(i int) {
 	c.Zones = append(c.Zones[:i], c.Zones[i+1:]...)
+	c.forest = nil
 }
 
 func (c *Company) canDeliverGood() bool {
@@ -62,19 +62,54 @@ func (g *Game) Update(c *gin.Context, cu *user.User) (string, game.ActionType, e
 	case "select-area":
 		return g.selectArea(c, cu)
 	case "select-hull-player":
-		return g.selectHullPlayer(c, cu)
+		p := g.PlayerBySID(c.PostForm("id"))
+		if p == nil {
+			return "indonesia/flash_notice", game.None, sn.NewVError("Received invalid player.")
+		}
+		return g.update(c, cu, SelectHullPlayer{PlayerID: p.ID()})
 	case "turn-order-bid":
-		return g.placeTurnOrderBid(c, cu)
+		if g.SealedBids {
+			return "indonesia/flash_notice", game.None,
+				sn.NewVError("This game uses sealed bids; place your bid through /sealed-bid/%s.", c.Param(hParam))
+		}
+		bid, err := strconv.Atoi(c.PostForm("Bid"))
+		if err != nil {
+			return "indonesia/flash_notice", game.None, err
+		}
+		return g.update(c, cu, TurnOrderBid{Bid: bid})
 	case "stop-expanding":
-		return g.stopExpanding(c, cu)
+		return g.update(c, cu, StopExpanding{})
 	case "accept-proposed-flow":
-		return g.acceptProposedFlow(c, cu)
+		return g.update(c, cu, AcceptProposedFlow{})
+	case "accept-alternative-flow":
+		plan, err := strconv.Atoi(c.PostForm("plan"))
+		if err != nil {
+			return "indonesia/flash_notice", game.None, sn.NewVError("Received invalid delivery plan.")
+		}
+		return g.update(c, cu, AcceptAlternativeFlow{Plan: plan})
+	case "remove-rice-spice-set":
+		var ids AreaIDS
+		for _, v := range c.PostFormArray("areas") {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				return "indonesia/flash_notice", game.None, sn.NewVError("Received invalid removal set.")
+			}
+			ids = append(ids, AreaID(id))
+		}
+		return g.update(c, cu, RemoveRiceSpiceSet{Areas: ids})
 	case "city-growth":
-		return g.cityGrowth(c, cu)
+		return g.update(c, cu, GrowCities{Areas: g.cityGrowthSelection(c)})
 	case "pass":
-		return g.pass(c, cu)
+		return g.update(c, cu, Pass{})
 	case "merger-bid":
-		return g.mergerBid(c, cu)
+		bid := NoBid
+		if v := c.PostForm("bid"); v != "none" {
+			var err error
+			if bid, err = strconv.Atoi(v); err != nil {
+				return "indonesia/flash_notice", game.None, err
+			}
+		}
+		return g.update(c, cu, MergerBid{Bid: bid})
 	case "undo":
 		return g.undoAction(c, cu)
 	case "redo":
@@ -87,6 +122,10 @@ func (g *Game) Update(c *gin.Context, cu *user.User) (string, game.ActionType, e
 		return g.adminCities(c, cu)
 	case "admin-area":
 		return g.adminArea(c, cu)
+	case "admin-replay":
+		return g.adminReplay(c, cu)
+	case "admin-encoding":
+		return g.adminEncoding(c, cu)
 		//	case "admin-company":
 		//		tmpl, act, err = g.adminCompany(c)
 		//	"admin-patch":              adminPatch,
@@ -97,6 +136,16 @@ func (g *Game) Update(c *gin.Context, cu *user.User) (string, game.ActionType, e
 	}
 }
 
+// update applies cmd on behalf of user cu and adds the announced log entries as notices.
+func (g *Game) update(c *gin.Context, cu *user.User, cmd Command) (string, game.ActionType, error) {
+	tmpl, es, err := g.apply(g.playerIDFor(cu), cmd)
+	if err != nil {
+		return tmpl, game.None, err
+	}
+	addNotices(c, es)
+	return tmpl, game.Cache, nil
+}
+
 func (client *Client) show(prefix string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		client.Log.Debugf(msgEnter)
@@ -108,7 +157,7 @@ func (client *Client) show(prefix string) gin.HandlerFunc {
 			return
 		}
 
-		ml, err := client.MLog.Get(c, id)
+		ml, err := client.getMLog(c, id)
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			return
@@ -119,11 +168,18 @@ func (client *Client) show(prefix string) gin.HandlerFunc {
 			client.Log.Debugf(err.Error())
 		}
 
+		g := gameFrom(c)
+		history := historyOf(prefix, c.Param(hParam), g)
+		if history != nil {
+			history.links(c)
+		}
+
 		c.HTML(http.StatusOK, prefix+"/show", gin.H{
 			"Context":    c,
 			"VersionID":  sn.VersionID(),
 			"CUser":      cu,
-			"Game":       gameFrom(c),
+			"Game":       g,
+			"History":    history,
 			"IsAdmin":    cu.IsAdmin(),
 			"Admin":      game.AdminFrom(c),
 			"MessageLog": ml,
@@ -150,7 +206,7 @@ func (client *Client) addMessage(prefix string) gin.HandlerFunc {
 			return
 		}
 
-		ml, err := client.MLog.Get(c, id)
+		ml, err := client.getMLog(c, id)
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			return
@@ -158,7 +214,7 @@ func (client *Client) addMessage(prefix string) gin.HandlerFunc {
 
 		m := ml.AddMessage(cu, c.PostForm("message"))
 
-		_, err = client.MLog.Put(c, id, ml)
+		err = client.putMLog(c, ml)
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			return
@@ -173,6 +229,42 @@ func (client *Client) addMessage(prefix string) gin.HandlerFunc {
 	}
 }
 
+// getMLog loads the message log of the game having id from the store of the client.
+func (client *Client) getMLog(c *gin.Context, id int64) (*mlog.MLog, error) {
+	ml := mlog.New(id)
+	return ml, client.Store.Get(c, ml.Key, ml)
+}
+
+// putMLog saves the message log ml to the store of the client.
+func (client *Client) putMLog(c *gin.Context, ml *mlog.MLog) error {
+	return client.Store.RunInTransaction(c, func(tx StoreTx) error {
+		return tx.PutMulti([]*datastore.Key{ml.Key}, []interface{}{ml})
+	})
+}
+
+// statsFetch loads the stats of the current user from the store of the client,
+// for handlers saving them with a game.
+func (client *Client) statsFetch(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	cu, err := client.User.Current(c)
+	if cu == nil {
+		client.Log.Debugf("missing user: %v", err)
+		restful.AddErrorf(c, "missing user.")
+		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("missing user."))
+		return
+	}
+
+	s := user.NewStatsFor(cu)
+	if err := client.Store.Get(c, s.Key, s); err != nil && err != datastore.ErrNoSuchEntity {
+		restful.AddErrorf(c, err.Error())
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	user.StatsWith(c, s)
+}
+
 func (client *Client) update(prefix string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		client.Log.Debugf(msgEnter)
@@ -199,9 +291,12 @@ func (client *Client) update(prefix string) gin.HandlerFunc {
 			client.Log.Errorf(err.Error())
 			c.Redirect(http.StatusSeeOther, homePath)
 			return
-		case actionType == game.Cache:
-			mkey := g.UndoKey(cu)
-			client.Cache.SetDefault(mkey, g)
+		case actionType == game.Cache, actionType == game.Undo, actionType == game.Redo, actionType == game.Reset:
+			err := client.updateTurn(c, g, cu, actionType)
+			if err != nil {
+				client.Log.Errorf(err.Error())
+				restful.AddErrorf(c, err.Error())
+			}
 		case actionType == game.Save:
 			err := client.save(c, g, cu)
 			if err != nil {
@@ -210,9 +305,6 @@ func (client *Client) update(prefix string) gin.HandlerFunc {
 				c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
 				return
 			}
-		case actionType == game.Undo:
-			mkey := g.UndoKey(cu)
-			client.Cache.Delete(mkey)
 		}
 
 		switch jData := jsonFrom(c); {
@@ -239,7 +331,14 @@ func (client *Client) update(prefix string) gin.HandlerFunc {
 	}
 }
 func (client *Client) save(c *gin.Context, g *Game, cu *user.User) error {
-	_, err := client.DS.RunInTransaction(c, func(tx *datastore.Transaction) error {
+	return client.saveWith(c, g, cu, nil, nil)
+}
+
+// saveWith saves g together with the entities es under the keys ks, provided g
+// has not changed since it was loaded.
+func (client *Client) saveWith(c *gin.Context, g *Game, cu *user.User, ks []*datastore.Key, es []interface{}) error {
+	recorded := g.Recorded
+	err := client.Store.RunInTransaction(c, func(tx StoreTx) error {
 		oldG := New(c, g.ID())
 		err := tx.Get(oldG.Key, oldG.Header)
 		if err != nil {
@@ -250,62 +349,71 @@ func (client *Client) save(c *gin.Context, g *Game, cu *user.User) error {
 			return fmt.Errorf("Game state changed unexpectantly.  Try again.")
 		}
 
-		err = g.encode(c)
-		if err != nil {
-			return err
-		}
-
-		_, err = tx.Put(g.Key, g.Header)
-		if err != nil {
-			return err
-		}
-
-		mkey := g.UndoKey(cu)
-		client.Cache.Delete(mkey)
-		return nil
+		return putGame(tx, g, recorded, ks, es)
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	return client.Turns.Reset(c, g.UndoKey(cu))
 }
 
-func (client *Client) saveWith(c *gin.Context, g *Game, cu *user.User, ks []*datastore.Key, es []interface{}) error {
-	_, err := client.DS.RunInTransaction(c, func(tx *datastore.Transaction) error {
-		oldG := New(c, g.ID())
-		err := tx.Get(oldG.Key, oldG.Header)
-		if err != nil {
-			return err
+// saveAttempts bounds the attempts to save an update of a stored game racing other updates.
+const saveAttempts = 3
+
+// retrySave loads the stored game having id, updates it by f and saves it if f
+// reports a change, starting over from the stored game when the save races an
+// update of another player, such as a sealed bid.  A game completed by f is saved
+// with its contests.  It returns the game as saved, or nil if f made no change.
+func (client *Client) retrySave(c *gin.Context, id int64, cu *user.User, f func(*Game) (Events, bool, error)) (*Game, error) {
+	for attempt := 1; ; attempt++ {
+		g := New(c, id)
+		if err := client.dsGet(c, g); err != nil {
+			client.Log.Errorf(err.Error())
+			return nil, err
 		}
 
-		if oldG.UpdatedAt != g.UpdatedAt {
-			return fmt.Errorf("Game state changed unexpectantly.  Try again.")
+		es, changed, err := f(g)
+		if err != nil || !changed {
+			return nil, err
 		}
 
-		err = g.encode(c)
-		if err != nil {
-			return err
+		var ks []*datastore.Key
+		var cs []interface{}
+		if g.Status == game.Completed {
+			places, err := client.determinePlaces(c, g)
+			if err != nil {
+				return nil, err
+			}
+			ks, cs = wrap(nil, contest.GenContests(c, places))
 		}
 
-		ks = append(ks, g.Key)
-		es = append(es, g.Header)
-
-		_, err = tx.PutMulti(ks, es)
-		if err != nil {
-			return err
+		err = client.saveWith(c, g, cu, ks, cs)
+		if err == nil {
+			addNotices(c, es)
+			return g, nil
 		}
-
-		mkey := g.UndoKey(cu)
-		client.Cache.Delete(mkey)
-		return nil
-	})
-	return err
+		if attempt == saveAttempts {
+			client.Log.Errorf(err.Error())
+			return nil, err
+		}
+	}
 }
 
 func (g *Game) encode(c *gin.Context) (err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
+	// The journal is stored in record pages by putGame.
 	g.TempData = nil
+	s := *g.State
+	s.Journal = nil
 	var encoded []byte
-	if encoded, err = codec.Encode(g.State); err != nil {
+	if g.Encoding == jsonEncoding {
+		encoded, err = encodeStateJSON(&s)
+	} else {
+		encoded, err = codec.Encode(&s)
+	}
+	if err != nil {
 		return
 	}
 	g.SavedState = encoded
@@ -314,15 +422,37 @@ func (g *Game) encode(c *gin.Context) (err error) {
 	return
 }
 
+// decode restores the state of g saved by encode.
+func (g *Game) decode() error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if isJSONState(g.SavedState) {
+		s, err := decodeStateJSON(g.SavedState)
+		if err != nil {
+			return err
+		}
+		g.State = s
+		return nil
+	}
+
+	s := newState()
+	if err := codec.Decode(&s, g.SavedState); err != nil {
+		return err
+	}
+	g.State = s
+	return nil
+}
+
+// wrap returns the keys and entities of the stats s, if any, and of the contests cs.
 func wrap(s *user.Stats, cs []*contest.Contest) ([]*datastore.Key, []interface{}) {
-	l := len(cs) + 1
-	es := make([]interface{}, l)
-	ks := make([]*datastore.Key, l)
-	es[0] = s
-	ks[0] = s.Key
-	for i, c := range cs {
-		es[i+1] = c
-		ks[i+1] = c.Key
+	var ks []*datastore.Key
+	var es []interface{}
+	if s != nil {
+		ks, es = append(ks, s.Key), append(es, s)
+	}
+	for _, c := range cs {
+		ks, es = append(ks, c.Key), append(es, c)
 	}
 	return ks, es
 }
@@ -362,8 +492,11 @@ func (client *Client) undo(prefix string) gin.HandlerFunc {
 			return
 		}
 
-		mkey := g.UndoKey(cu)
-		client.Cache.Delete(mkey)
+		err = client.Turns.Reset(c, g.UndoKey(cu))
+		if err != nil {
+			client.Log.Errorf(err.Error())
+			restful.AddErrorf(c, err.Error())
+		}
 		c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
 	}
 }
@@ -447,28 +580,33 @@ func (client *Client) create(prefix string) gin.HandlerFunc {
 			c.Redirect(http.StatusSeeOther, recruitingPath(prefix))
 			return
 		}
-		err = g.encode(c)
+		g.SealedBids = c.PostForm("sealed-bids") == "on"
+		if c.PostForm("encoding") == jsonEncoding {
+			g.Encoding = jsonEncoding
+		}
+
+		start, err := g.addBotsFrom(c)
 		if err != nil {
 			client.Log.Errorf(err.Error())
+			restful.AddErrorf(c, err.Error())
 			c.Redirect(http.StatusSeeOther, recruitingPath(prefix))
 			return
 		}
 
-		ks, err := client.DS.AllocateIDs(c, []*datastore.Key{g.Key})
+		if start {
+			g.Start()
+		}
+
+		g.Key, err = client.Store.AllocateID(c, g.Key)
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			c.Redirect(http.StatusSeeOther, recruitingPath(prefix))
 			return
 		}
 
-		k := ks[0]
-
-		_, err = client.DS.RunInTransaction(c, func(tx *datastore.Transaction) error {
-			m := mlog.New(k.ID)
-			ks := []*datastore.Key{m.Key, k}
-			es := []interface{}{m, g.Header}
-			_, err := tx.PutMulti(ks, es)
-			return err
+		err = client.Store.RunInTransaction(c, func(tx StoreTx) error {
+			m := mlog.New(g.Key.ID)
+			return putGame(tx, g, 0, []*datastore.Key{m.Key}, []interface{}{m})
 		})
 		if err != nil {
 			client.Log.Errorf(err.Error())
@@ -477,6 +615,9 @@ func (client *Client) create(prefix string) gin.HandlerFunc {
 		}
 
 		restful.AddNoticef(c, "<div>%s created.</div>", g.Title)
+		if start {
+			client.playStoredBots(c, g.ID(), cu)
+		}
 		c.Redirect(http.StatusSeeOther, recruitingPath(prefix))
 	}
 }
@@ -502,7 +643,12 @@ func (client *Client) accept(prefix string) gin.HandlerFunc {
 			return
 		}
 
-		start, err := g.Accept(c, cu)
+		var start bool
+		if c.PostForm("bot") == "true" {
+			start, err = g.acceptBot(c, cu)
+		} else {
+			start, err = g.Accept(c, cu)
+		}
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			restful.AddErrorf(c, err.Error())
@@ -511,13 +657,7 @@ func (client *Client) accept(prefix string) gin.HandlerFunc {
 		}
 
 		if start {
-			err = client.Start(c, g)
-			if err != nil {
-				client.Log.Errorf(err.Error())
-				restful.AddErrorf(c, err.Error())
-				c.Redirect(http.StatusSeeOther, recruitingPath(prefix))
-				return
-			}
+			g.Start()
 		}
 
 		err = client.save(c, g, cu)
@@ -529,6 +669,10 @@ func (client *Client) accept(prefix string) gin.HandlerFunc {
 		}
 
 		if start {
+			// The game is saved started before the bots play, so a failing bot never loses the acceptance.
+			if played := client.playStoredBots(c, g.ID(), cu); played != nil {
+				g = played
+			}
 			err = g.SendTurnNotificationsTo(c, g.CurrentPlayer())
 			if err != nil {
 				client.Log.Warningf(err.Error())
@@ -589,7 +733,6 @@ func (client *Client) fetch(c *gin.Context) {
 	}
 
 	g := New(c, id)
-	t := g.Type
 
 	cu, err := client.User.Current(c)
 	if err != nil {
@@ -598,11 +741,7 @@ func (client *Client) fetch(c *gin.Context) {
 
 	switch action := c.PostForm("action"); {
 	case action == "reset":
-		// pull from cache/datastore
-		// same as undo & !MultiUndo
-		fallthrough
-	case action == "undo" && !t.MultiUndo():
-		// pull from cache/datastore
+		// pull from datastore
 		err := client.dsGet(c, g)
 		if err != nil {
 			c.Redirect(http.StatusSeeOther, homePath)
@@ -625,30 +764,30 @@ func (client *Client) fetch(c *gin.Context) {
 	}
 }
 
-// pull temporary game state from cache.  Note may be different from value stored in datastore.
+// pull working state of turn in progress from turn cache.  Note may be different from value stored in datastore.
 func (client *Client) mcGet(c *gin.Context, g *Game, cu *user.User) error {
 	client.Log.Debugf(msgEnter)
 	defer client.Log.Debugf(msgExit)
 
-	mkey := g.UndoKey(cu)
-	item, found := client.Cache.Get(mkey)
-	if !found {
+	state, found, err := client.Turns.Get(c, g.UndoKey(cu))
+	switch {
+	case err != nil:
+		return err
+	case !found:
 		return fmt.Errorf("game not found")
 	}
 
-	g2, ok := item.(*Game)
-	if !ok {
-		return fmt.Errorf("item not a *Game")
+	err = g.decodeTurn(state)
+	if err != nil {
+		return err
 	}
-	g2.SetCTX(c)
 
-	g = g2
-	cu, err := client.User.Current(c)
+	err = client.AfterCache(c, g)
 	if err != nil {
-		client.Log.Debugf(err.Error())
+		return err
 	}
-	cmap := g.ColorMapFor(cu)
 
+	cmap := g.ColorMapFor(cu)
 	c = withGame(c, g)
 	c = color.WithMap(c, cmap)
 	return nil
@@ -659,22 +798,24 @@ func (client *Client) dsGet(c *gin.Context, g *Game) error {
 	client.Log.Debugf(msgEnter)
 	defer client.Log.Debugf(msgExit)
 
-	switch err := client.DS.Get(c, g.Key, g.Header); {
+	switch err := client.Store.Get(c, g.Key, g.Header); {
 	case err != nil:
 		restful.AddErrorf(c, err.Error())
 		return err
 	case g == nil:
-		err := fmt.Errorf("Unable to get game for id: %v", g.ID)
+		err := fmt.Errorf("Unable to get game for id: %v", g.ID())
 		restful.AddErrorf(c, err.Error())
 		return err
 	}
 
-	s := newState()
-	if err := codec.Decode(&s, g.SavedState); err != nil {
+	if err := g.decode(); err != nil {
+		restful.AddErrorf(c, err.Error())
+		return err
+	}
+
+	if err := client.Store.RunInTransaction(c, g.getJournal); err != nil {
 		restful.AddErrorf(c, err.Error())
 		return err
-	} else {
-		g.State = s
 	}
 
 	err := client.init(c, g)
@@ -683,6 +824,11 @@ func (client *Client) dsGet(c *gin.Context, g *Game) error {
 		return err
 	}
 
+	if _, err := g.migrate(); err != nil {
+		restful.AddErrorf(c, err.Error())
+		return err
+	}
+
 	cu, err := client.User.Current(c)
 	if err != nil {
 		client.Log.Debugf(err.Error())
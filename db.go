@@ -13,6 +13,7 @@ func New(c *gin.Context, id int64) *Game {
 	g := new(Game)
 	g.Header = game.NewHeader(c, g, id)
 	g.State = newState()
+	g.SchemaVersion = CurrentSchemaVersion
 	g.Key.Parent = pk(c)
 	g.Type = gtype.Indonesia
 	return g
@@ -42,6 +43,12 @@ func (client *Client) init(c *gin.Context, g *Game) error {
 		return err
 	}
 
+	g.initState()
+	return nil
+}
+
+// initState points the players, areas and mergers of a decoded state back at g.
+func (g *Game) initState() {
 	for _, player := range g.Players() {
 		player.Init(g)
 	}
@@ -54,7 +61,6 @@ func (client *Client) init(c *gin.Context, g *Game) error {
 	if g.SiapFajiMerger != nil {
 		g.SiapFajiMerger.init(g)
 	}
-	return nil
 }
 
 func (client *Client) AfterCache(c *gin.Context, g *Game) error {
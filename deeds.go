@@ -1,7 +1,6 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"fmt"
 	"html/template"
 	"strings"
@@ -11,7 +10,7 @@ import (
 )
 
 func init() {
-	gob.Register(new(removeDeedsEntry))
+	registerType(new(removeDeedsEntry))
 }
 
 type MaxShips map[Era]int
@@ -0,0 +1,218 @@
+package indonesia
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeliveryPlan describes how the goods of a company reach the cities.
+type DeliveryPlan struct {
+	OwnerID    int        `json:"ownerID"`
+	Slot       int        `json:"slot"`
+	Goods      Goods      `json:"goods"`
+	Deliveries []Delivery `json:"deliveries"`
+
+	// Income maps the id of each player earning from the plan to the rupiah earned.
+	Income map[int]int `json:"income"`
+}
+
+// Delivery is the route of a single good, from the area producing it,
+// across the sea areas in order, to the city receiving it.
+type Delivery struct {
+	Producer AreaID `json:"producer"`
+	Legs     []Leg  `json:"legs"`
+	City     AreaID `json:"city"`
+}
+
+// Leg is a sea area crossed by a good and the ship carrying it there.
+// Shipper is the index of the ship among the shippers of the area.
+type Leg struct {
+	Area     AreaID   `json:"area"`
+	Shipper  int      `json:"shipper"`
+	OwnerID  int      `json:"ownerID"`
+	Province Province `json:"province"`
+}
+
+// ProposedPlan returns the proposed delivery plan of the selected company, if any.
+func (g *Game) ProposedPlan() *DeliveryPlan {
+	if g.TempData == nil || g.ProposedPath == nil {
+		return nil
+	}
+	return g.deliveryPlan(g.SelectedCompany(), g.ProposedPath)
+}
+
+// AlternativePlans returns the alternative delivery plans of the selected company,
+// in the order selected by AcceptAlternativeFlow.
+func (g *Game) AlternativePlans() []*DeliveryPlan {
+	if g.TempData == nil {
+		return nil
+	}
+	var plans []*DeliveryPlan
+	for _, fm := range g.AlternativePaths {
+		if plan := g.deliveryPlan(g.SelectedCompany(), fm); plan != nil {
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+func (g *Game) deliveryPlan(com *Company, fm flowMatrix) *DeliveryPlan {
+	if com == nil || com.IsShippingCompany() {
+		return nil
+	}
+
+	plan := &DeliveryPlan{
+		OwnerID: com.OwnerID,
+		Slot:    com.Slot,
+		Goods:   com.Goods(),
+		Income:  make(map[int]int, 0),
+	}
+
+	// Goods are taken from the areas of each zone in order, as when the plan is accepted.
+	taken := make(map[AreaID]int, 0)
+	for _, route := range fm.routes() {
+		d := Delivery{Producer: NoArea, City: NoArea}
+		for _, fid := range route {
+			switch {
+			case fid == sourceFID || fid == targetFID:
+			case seaSet.has(fid.AreaID):
+				if fid.IO != shipInput {
+					continue
+				}
+				d.Legs = append(d.Legs, Leg{Area: fid.AreaID, Shipper: fid.Index, OwnerID: fid.PID, Province: fid.Province})
+				if fid.PID != com.OwnerID {
+					plan.Income[fid.PID] += shipFee
+					plan.Income[com.OwnerID] -= shipFee
+				}
+			case d.Producer == NoArea:
+				d.Producer = fid.AreaID
+				if zone := com.ZoneFor(g.GetArea(fid.AreaID)); zone != nil && taken[fid.AreaID] < len(zone.AreaIDS) {
+					d.Producer = zone.AreaIDS[taken[fid.AreaID]]
+				}
+				taken[fid.AreaID]++
+			default:
+				d.City = fid.AreaID
+			}
+		}
+		plan.Deliveries = append(plan.Deliveries, d)
+		plan.Income[com.OwnerID] += com.Goods().Price()
+	}
+	return plan
+}
+
+// routes splits fm into the paths of single goods from source to target.
+// Circulations, which deliver nothing, are dropped.
+func (fm flowMatrix) routes() []FlowIDS {
+	left := make(flowMatrix, 0)
+	for u, sf := range fm {
+		for v, flow := range sf {
+			if flow > 0 {
+				if _, ok := left[u]; !ok {
+					left[u] = make(subflow, 0)
+				}
+				left[u][v] = flow
+			}
+		}
+	}
+
+	var routes []FlowIDS
+	for {
+		path := FlowIDS{sourceFID}
+		index := map[FlowID]int{sourceFID: 0}
+		for u := sourceFID; u != targetFID; {
+			v, ok := left.next(u)
+			if !ok {
+				return routes
+			}
+			if i, seen := index[v]; seen {
+				cycle := append(append(FlowIDS{}, path[i:]...), v)
+				left.remove(cycle, left.bottleneck(cycle))
+				for _, fid := range path[i+1:] {
+					delete(index, fid)
+				}
+				path, u = path[:i+1], v
+				continue
+			}
+			index[v] = len(path)
+			path, u = append(path, v), v
+		}
+		left.remove(path, 1)
+		routes = append(routes, path)
+	}
+}
+
+// next returns the least node receiving flow from u.
+func (fm flowMatrix) next(u FlowID) (FlowID, bool) {
+	var fids FlowIDS
+	for v, flow := range fm[u] {
+		if flow > 0 {
+			fids = append(fids, v)
+		}
+	}
+	if len(fids) == 0 {
+		return noFID, false
+	}
+	sort.Slice(fids, func(i, j int) bool { return fids[i].less(fids[j]) })
+	return fids[0], true
+}
+
+func (fm flowMatrix) bottleneck(path FlowIDS) int {
+	flow := infinity
+	for i := 1; i < len(path); i++ {
+		flow = min(flow, fm[path[i-1]][path[i]])
+	}
+	return flow
+}
+
+func (fm flowMatrix) remove(path FlowIDS, flow int) {
+	for i := 1; i < len(path); i++ {
+		fm[path[i-1]][path[i]] -= flow
+	}
+}
+
+func (fid FlowID) less(other FlowID) bool {
+	switch {
+	case fid.AreaID != other.AreaID:
+		return fid.AreaID < other.AreaID
+	case fid.PID != other.PID:
+		return fid.PID < other.PID
+	case fid.Index != other.Index:
+		return fid.Index < other.Index
+	case fid.IO != other.IO:
+		return fid.IO < other.IO
+	default:
+		return fid.Province < other.Province
+	}
+}
+
+// MarshalJSON encodes g, adding the delivery plans of the selected company
+// and leaving sealed bids out of the journal until they are revealed.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	type jGame Game
+	return json.Marshal(struct {
+		*jGame
+		Journal          Journal
+		ProposedPlan     *DeliveryPlan   `json:"proposedPlan,omitempty"`
+		AlternativePlans []*DeliveryPlan `json:"alternativePlans,omitempty"`
+	}{(*jGame)(g), g.openJournal(), g.ProposedPlan(), g.AlternativePlans()})
+}
+
+// deliveryPlans responds with the delivery plans of the selected company.
+func (client *Client) deliveryPlans(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g := gameFrom(c)
+	if g == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proposed":     g.ProposedPlan(),
+		"alternatives": g.AlternativePlans(),
+	})
+}
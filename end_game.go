@@ -1,12 +1,13 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"fmt"
 	"html/template"
 
-	"github.com/SlothNinja/contest"
+	"sort"
+
 	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/send"
 	"github.com/gin-gonic/gin"
@@ -14,27 +15,28 @@ import (
 )
 
 func init() {
-	gob.Register(new(endGameEntry))
-	gob.Register(new(announceWinnersEntry))
+	registerType(new(endGameEntry))
+	registerType(new(announceWinnersEntry))
 	//gob.Register(new(doubleIncomeEntry))
-	gob.Register(new(doubleFinalIncomeEntry))
+	registerType(new(doubleFinalIncomeEntry))
 }
 
-func (client *Client) endGame(c *gin.Context, g *Game) ([]*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+// endGame doubles final income, orders the players by score, and announces the winner.
+// Ratings and contests are left to the web client, which has access to the datastore.
+func (g *Game) endGame() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
 	g.Phase = EndGame
 
 	g.doubleFinalIncome()
 
-	places, err := client.determinePlaces(c, g)
-	if err != nil {
-		return nil, err
-	}
-	g.SetWinners(places[0])
-	cs := contest.GenContests(c, places)
+	ps := g.Players()
+	sort.Stable(Reverse{ByScore{ps}})
+	g.setPlayers(ps)
+	g.setWinners(ps[0])
 	g.newEndGameEntry()
-	return cs, nil
+	g.Phase = GameOver
 }
 
 func (g *Game) doubleFinalIncome() {
@@ -76,14 +78,13 @@ func (e *endGameEntry) HTML(c *gin.Context) template.HTML {
 	return restful.HTML("")
 }
 
-func (g *Game) SetWinners(rmap contest.ResultsMap) {
+func (g *Game) setWinners(ps ...*Player) {
 	g.Phase = AnnounceWinners
 	g.Status = game.Completed
 
 	g.setCurrentPlayers()
 	g.WinnerIDS = nil
-	for key := range rmap {
-		p := g.PlayerByUserID(key.ID)
+	for _, p := range ps {
 		g.WinnerIDS = append(g.WinnerIDS, p.ID())
 	}
 
@@ -94,7 +95,7 @@ func (g *Game) SendEndGameNotifications(c *gin.Context) error {
 	g.Phase = GameOver
 	g.Status = game.Completed
 
-	ms := make([]mailjet.InfoMessagesV31, len(g.Players()))
+	var ms []mailjet.InfoMessagesV31
 	subject := fmt.Sprintf("SlothNinja Games: Indonesia #%d Has Ended", g.ID())
 
 	var body string
@@ -108,9 +109,12 @@ func (g *Game) SendEndGameNotifications(c *gin.Context) error {
 	}
 	body += fmt.Sprintf("\nCongratulations to: %s.", restful.ToSentence(names))
 
-	for i, p := range g.Players() {
+	for _, p := range g.Players() {
+		if g.IsBot(p) {
+			continue
+		}
 		u := p.User()
-		ms[i] = mailjet.InfoMessagesV31{
+		ms = append(ms, mailjet.InfoMessagesV31{
 			From: &mailjet.RecipientV31{
 				Email: "webmaster@slothninja.com",
 				Name:  "Webmaster",
@@ -123,7 +127,7 @@ func (g *Game) SendEndGameNotifications(c *gin.Context) error {
 			},
 			Subject:  subject,
 			TextPart: body,
-		}
+		})
 	}
 	_, err := send.Messages(c, ms...)
 	return err
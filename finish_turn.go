@@ -28,17 +28,31 @@ func (client *Client) finish(prefix string) gin.HandlerFunc {
 			return
 		}
 
-		s, cs, err := client.finishTurn(c, g, cu)
+		s := user.StatsFetched(c)
+		if s == nil {
+			client.Log.Errorf("missing stats for player.")
+			c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
+			return
+		}
+
+		_, es, err := g.apply(g.playerIDFor(cu), FinishTurn{})
 		if err != nil {
 			client.Log.Errorf(err.Error())
 			c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
 			return
 		}
 
-		// Game is over if cs != nil
-		if cs != nil {
-			g.Phase = GameOver
-			g.Status = game.Completed
+		restful.AddNoticef(c, "%s finished turn.", g.NameFor(oldCP))
+		addNotices(c, es)
+
+		if g.Status == game.Completed {
+			places, err := client.determinePlaces(c, g)
+			if err != nil {
+				client.Log.Errorf(err.Error())
+				c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
+				return
+			}
+			cs := contest.GenContests(c, places)
 			ks, es := wrap(s.GetUpdate(c, g.UpdatedAt), cs)
 			err = client.saveWith(c, g, cu, ks, es)
 			if err != nil {
@@ -62,8 +76,13 @@ func (client *Client) finish(prefix string) gin.HandlerFunc {
 			return
 		}
 
+		// The finished turn is saved before the bots play, so a failing bot never loses it.
+		if played := client.playStoredBots(c, g.ID(), cu); played != nil {
+			g = played
+		}
+
 		newCP := g.CurrentPlayer()
-		if newCP != nil && oldCP.ID() != newCP.ID() {
+		if newCP != nil && oldCP.ID() != newCP.ID() && !g.IsBot(newCP) {
 			err = g.SendTurnNotificationsTo(c, newCP)
 			if err != nil {
 				client.Log.Warningf(err.Error())
@@ -74,53 +93,73 @@ func (client *Client) finish(prefix string) gin.HandlerFunc {
 	}
 }
 
-func (client *Client) finishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) finishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	switch {
+	case g.Phase == NewEra:
+		return g.newEraFinishTurn(cp)
+	case g.Phase == BidForTurnOrder:
+		return g.bidForTurnOrderFinishTurn(cp)
+	case g.Phase == Mergers && g.SubPhase == MBid:
+		return g.mergersBidFinishTurn(cp)
+	case g.Phase == Mergers:
+		return g.mergersFinishTurn(cp)
+	case g.Phase == Acquisitions:
+		return g.acquisitionsFinishTurn(cp)
+	case g.Phase == Research:
+		return g.researchFinishTurn(cp)
+	case g.Phase == Operations:
+		return g.companyExpansionFinishTurn(cp)
+	case g.Phase == CityGrowth:
+		return g.cityGrowthFinishTurn(cp)
+	default:
+		return sn.NewVError("Improper Phase for finishing turn.")
+	}
+}
 
+// validateFinishTurnFor validates finishing the turn of cp in the current phase.
+func (g *Game) validateFinishTurnFor(cp *Player) error {
 	switch {
 	case g.Phase == NewEra:
-		return client.newEraFinishTurn(c, g, cu)
+		return g.validateNewEraFinishTurn(cp)
 	case g.Phase == BidForTurnOrder:
-		return client.bidForTurnOrderFinishTurn(c, g, cu)
+		return g.validateBidForTurnOrderFinishTurn(cp)
 	case g.Phase == Mergers && g.SubPhase == MBid:
-		return client.mergersBidFinishTurn(c, g, cu)
+		return g.validateMergersBidFinishTurn(cp)
 	case g.Phase == Mergers:
-		return client.mergersFinishTurn(c, g, cu)
+		return g.validateMergersFinishTurn(cp)
 	case g.Phase == Acquisitions:
-		return client.acquisitionsFinishTurn(c, g, cu)
+		return g.validateAcquisitionsFinishTurn(cp)
 	case g.Phase == Research:
-		return client.researchFinishTurn(c, g, cu)
+		return g.validateResearchFinishTurn(cp)
 	case g.Phase == Operations:
-		return client.companyExpansionFinishTurn(c, g, cu)
+		return g.validateCompanyExpansionFinishTurn(cp)
 	case g.Phase == CityGrowth:
-		return client.cityGrowthFinishTurn(c, g, cu)
+		return g.validateCityGrowthFinishTurn(cp)
 	default:
-		return nil, nil, sn.NewVError("Improper Phase for finishing turn.")
+		return sn.NewVError("Improper Phase for finishing turn.")
 	}
 }
 
-func (g *Game) validateFinishTurn(c *gin.Context, cu *user.User) (*user.Stats, error) {
+func (g *Game) validateFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	cp := g.CurrentPlayer()
-	s := user.StatsFetched(c)
 	switch {
-	case s == nil:
-		return nil, sn.NewVError("missing stats for player.")
-	case !g.IsCurrentPlayer(cu):
-		return nil, sn.NewVError("only the current player may finish a turn.")
+	case !g.isCurrentPlayer(cp):
+		return sn.NewVError("only the current player may finish a turn.")
 	case !cp.PerformedAction:
-		return nil, sn.NewVError("%s has yet to perform an action.", g.NameFor(cp))
+		return sn.NewVError("%s has yet to perform an action.", g.NameFor(cp))
 	default:
-		return s, nil
+		return nil
 	}
 }
 
 // ps is an optional parameter.
 // If no player is provided, assume current player.
-func (g *Game) nextPlayer(cu *user.User, ps ...game.Playerer) *Player {
+func (g *Game) nextPlayer(ps ...game.Playerer) *Player {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -131,78 +170,77 @@ func (g *Game) nextPlayer(cu *user.User, ps ...game.Playerer) *Player {
 	return nil
 }
 
-func (g *Game) newEraNextPlayer(cu *user.User) *Player {
+func (g *Game) newEraNextPlayer() *Player {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu)
+	p := g.nextPlayer()
 	for g.Players().anyCanPlaceCity() {
 		if p.CanPlaceCity() {
 			p.beginningOfTurnReset()
 			return p
 		}
-		p = g.nextPlayer(cu, p)
+		p = g.nextPlayer(p)
 	}
 	return nil
 }
 
-func (g *Game) removeUnplayableCityCardsFor(c *gin.Context, p *Player) {
+func (g *Game) removeUnplayableCityCardsFor(p *Player) {
 	var newCityCards CityCards
 	for _, card := range p.CityCards {
 		if card.Era != g.Era {
 			newCityCards = append(newCityCards, card)
 		} else {
 			e := g.newDiscardCityEntryFor(p, card)
-			restful.AddNoticef(c, string(e.HTML(c)))
+			g.emit(e)
 		}
 	}
 	p.CityCards = newCityCards
 }
 
-func (client *Client) newEraFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) newEraFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateNewEraFinishTurn(c, cu)
+	err := g.validateNewEraFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
+	if np := g.newEraNextPlayer(); np != nil {
+		g.setCurrentPlayers(np)
+		return nil
+	}
 
-	np := g.newEraNextPlayer(cu)
-	if np == nil {
-		for _, p := range g.Players() {
-			g.removeUnplayableCityCardsFor(c, p)
-		}
-		np = g.startBidForTurnOrder(c)
+	for _, p := range g.Players() {
+		g.removeUnplayableCityCardsFor(p)
 	}
-	g.setCurrentPlayers(np)
-	return s, nil, nil
+	g.startBidForTurnOrder()
+	return nil
 }
 
-func (g *Game) validateNewEraFinishTurn(c *gin.Context, cu *user.User) (*user.Stats, error) {
+func (g *Game) validateNewEraFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	s, err := g.validateFinishTurn(c, cu)
+	err := g.validateFinishTurn(cp)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if g.Phase != NewEra {
-		return nil, sn.NewVError(`expected "New Era" phase but have %q phase.`, g.Phase)
+		return sn.NewVError(`expected "New Era" phase but have %q phase.`, g.Phase)
 	}
-	return s, nil
+	return nil
 }
 
-func (g *Game) bidForTurnOrderNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
+func (g *Game) bidForTurnOrderNextPlayer(pers ...game.Playerer) *Player {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu, pers...)
+	p := g.nextPlayer(pers...)
 	for !p.Equal(g.Players()[0]) {
 		if !p.CanBid() {
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return p
 		}
@@ -210,40 +248,43 @@ func (g *Game) bidForTurnOrderNextPlayer(cu *user.User, pers ...game.Playerer) *
 	return nil
 }
 
-func (client *Client) bidForTurnOrderFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) bidForTurnOrderFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateBidForTurnOrderFinishTurn(c, cu)
+	err := g.validateBidForTurnOrderFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
+	if g.SealedBids {
+		g.sealedBidsFinishTurn(cp)
+		return nil
+	}
 
-	np := g.bidForTurnOrderNextPlayer(cu)
+	np := g.bidForTurnOrderNextPlayer()
 	if np == nil {
-		g.setTurnOrder(c, cu)
-		return s, nil, nil
+		g.setTurnOrder()
+		return nil
 	}
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateBidForTurnOrderFinishTurn(c *gin.Context, cu *user.User) (s *user.Stats, err error) {
-	if s, err = g.validateFinishTurn(c, cu); g.Phase != BidForTurnOrder {
+func (g *Game) validateBidForTurnOrderFinishTurn(cp *Player) (err error) {
+	if err = g.validateFinishTurn(cp); g.Phase != BidForTurnOrder {
 		err = sn.NewVError(`Expected "Bid For Turn Order" phase but have %q phase.`, g.Phase)
 	}
 	return
 }
 
-func (g *Game) mergersBidNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
+func (g *Game) mergersBidNextPlayer(pers ...game.Playerer) *Player {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu, pers...)
+	p := g.nextPlayer(pers...)
 	for !g.Players().allPassed() {
 		if !p.CanBidOnMerger() {
 			g.autoPass(p)
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return p
 		}
@@ -251,40 +292,38 @@ func (g *Game) mergersBidNextPlayer(cu *user.User, pers ...game.Playerer) *Playe
 	return nil
 }
 
-func (client *Client) mergersBidFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) mergersBidFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateMergersBidFinishTurn(c, cu)
+	err := g.validateMergersBidFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-
-	np := g.mergersBidNextPlayer(cu)
+	np := g.mergersBidNextPlayer()
 	if np == nil {
-		g.startMergerResolution(c, cu)
-		return s, nil, nil
+		g.startMergerResolution()
+		return nil
 	}
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateMergersBidFinishTurn(c *gin.Context, cu *user.User) (s *user.Stats, err error) {
-	if s, err = g.validateFinishTurn(c, cu); g.Phase != Mergers {
+func (g *Game) validateMergersBidFinishTurn(cp *Player) (err error) {
+	if err = g.validateFinishTurn(cp); g.Phase != Mergers {
 		err = sn.NewVError(`Expected "Mergers" phase but have %q phase.`, g.Phase)
 	}
 	return
 }
 
-func (g *Game) mergersNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
+func (g *Game) mergersNextPlayer(pers ...game.Playerer) *Player {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu, pers...)
+	p := g.nextPlayer(pers...)
 	for !g.Players().allPassed() {
 		if !p.CanAnnounceMerger() {
 			g.autoPass(p)
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return p
 		}
@@ -292,17 +331,15 @@ func (g *Game) mergersNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
 	return nil
 }
 
-func (client *Client) mergersFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) mergersFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateMergersFinishTurn(c, cu)
+	err := g.validateMergersFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-
 	if g.SubPhase == MSiapFajiCreation {
 		announcer := g.PlayerByID(g.Merger.AnnouncerID)
 		g.SiapFajiMerger = nil
@@ -310,50 +347,50 @@ func (client *Client) mergersFinishTurn(c *gin.Context, g *Game, cu *user.User)
 		g.setCurrentPlayers(announcer)
 		g.beginningOfPhaseReset()
 		g.SubPhase = MSelectCompany1
-		np := g.mergersNextPlayer(cu)
+		np := g.mergersNextPlayer()
 		if np != nil {
 			g.setCurrentPlayers(np)
-			return s, nil, nil
+			return nil
 		}
-		g.startAcquisitions(c, cu)
-		return s, nil, nil
+		g.startAcquisitions()
+		return nil
 	}
 
-	np := g.mergersNextPlayer(cu)
+	np := g.mergersNextPlayer()
 	if np == nil {
-		g.startAcquisitions(c, cu)
-		return s, nil, nil
+		g.startAcquisitions()
+		return nil
 	}
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateMergersFinishTurn(c *gin.Context, cu *user.User) (*user.Stats, error) {
+func (g *Game) validateMergersFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	s, err := g.validateFinishTurn(c, cu)
+	err := g.validateFinishTurn(cp)
 	switch {
 	case err != nil:
-		return nil, err
+		return err
 	case g.Phase != Mergers:
-		return nil, sn.NewVError(`Expected "Mergers" phase but have %q phase.`, g.Phase)
+		return sn.NewVError(`Expected "Mergers" phase but have %q phase.`, g.Phase)
 	case g.SubPhase == MSiapFajiCreation && g.SiapFajiMerger.GoodsToRemove() > 0:
-		return nil, sn.NewVError("you must remove %d more rice/spice", g.SiapFajiMerger.GoodsToRemove())
+		return sn.NewVError("you must remove %d more rice/spice", g.SiapFajiMerger.GoodsToRemove())
 	case g.SubPhase == MSiapFajiCreation && !g.SiapFajiMerger.Company().Zones.contiguous():
-		return nil, sn.NewVError("each zone must be contiguous after removal.")
+		return sn.NewVError("each zone must be contiguous after removal.")
 	default:
-		return s, nil
+		return nil
 	}
 }
 
-func (g *Game) acquisitionsNextPlayer(cu *user.User, pers ...game.Playerer) (p *Player) {
+func (g *Game) acquisitionsNextPlayer(pers ...game.Playerer) (p *Player) {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p = g.nextPlayer(cu, pers...)
+	p = g.nextPlayer(pers...)
 	for !g.Players().allPassed() {
 		if !p.CanAcquireCompany() {
 			g.autoPass(p)
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return
 		}
@@ -362,47 +399,45 @@ func (g *Game) acquisitionsNextPlayer(cu *user.User, pers ...game.Playerer) (p *
 	return
 }
 
-func (client *Client) acquisitionsFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) acquisitionsFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateAcquisitionsFinishTurn(c, cu)
+	err := g.validateAcquisitionsFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-
-	np := g.acquisitionsNextPlayer(cu)
+	np := g.acquisitionsNextPlayer()
 	if np == nil {
-		g.startResearch(c)
-		return s, nil, nil
+		g.startResearch()
+		return nil
 	}
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateAcquisitionsFinishTurn(c *gin.Context, cu *user.User) (*user.Stats, error) {
+func (g *Game) validateAcquisitionsFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	s, err := g.validateFinishTurn(c, cu)
+	err := g.validateFinishTurn(cp)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if g.Phase != Acquisitions {
-		return nil, sn.NewVError(`Expected "Acquisitions" phase but have %q phase.`, g.Phase)
+		return sn.NewVError(`Expected "Acquisitions" phase but have %q phase.`, g.Phase)
 	}
-	return s, nil
+	return nil
 }
 
-func (g *Game) researchNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
+func (g *Game) researchNextPlayer(pers ...game.Playerer) *Player {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu, pers...)
+	p := g.nextPlayer(pers...)
 	for !p.Equal(g.Players()[0]) {
 		if !p.CanResearch() {
 			g.autoPass(p)
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return p
 		}
@@ -410,47 +445,42 @@ func (g *Game) researchNextPlayer(cu *user.User, pers ...game.Playerer) *Player
 	return nil
 }
 
-func (client *Client) researchFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
+func (g *Game) researchFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	s, err := g.validateResearchFinishTurn(c, cu)
+	err := g.validateResearchFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-
-	np := g.researchNextPlayer(cu)
+	np := g.researchNextPlayer()
 	if np == nil {
-		cs, err := client.startOperations(c, g, cu)
-		if err != nil {
-			return nil, nil, err
-		}
-		return s, cs, nil
+		g.startOperations()
+		return nil
 	}
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateResearchFinishTurn(c *gin.Context, cu *user.User) (s *user.Stats, err error) {
+func (g *Game) validateResearchFinishTurn(cp *Player) (err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if s, err = g.validateFinishTurn(c, cu); g.Phase != Research {
+	if err = g.validateFinishTurn(cp); g.Phase != Research {
 		err = sn.NewVError(`Expected "Research" phase but have %q phase.`, g.Phase)
 	}
 	return
 }
 
-func (g *Game) companyExpansionNextPlayer(cu *user.User, pers ...game.Playerer) *Player {
+func (g *Game) companyExpansionNextPlayer(pers ...game.Playerer) *Player {
 	g.CurrentPlayer().endOfTurnUpdate()
-	p := g.nextPlayer(cu, pers...)
+	p := g.nextPlayer(pers...)
 	g.OverrideDeliveries = -1
 	for !g.AllCompaniesOperated() {
 		if !p.HasCompanyToOperate() {
 			g.autoPass(p)
-			p = g.nextPlayer(cu, p)
+			p = g.nextPlayer(p)
 		} else {
 			return p
 		}
@@ -458,24 +488,19 @@ func (g *Game) companyExpansionNextPlayer(cu *user.User, pers ...game.Playerer)
 	return nil
 }
 
-func (client *Client) companyExpansionFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) companyExpansionFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateCompanyExpansionFinishTurn(c, cu)
+	err := g.validateCompanyExpansionFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-
-	np := g.companyExpansionNextPlayer(cu)
+	np := g.companyExpansionNextPlayer()
 	if np == nil {
-		cs, err := client.startCityGrowth(c, g)
-		if err != nil {
-			return nil, nil, err
-		}
-		return s, cs, nil
+		g.startCityGrowth()
+		return nil
 	}
 
 	g.Phase = Operations
@@ -483,51 +508,46 @@ func (client *Client) companyExpansionFinishTurn(c *gin.Context, g *Game, cu *us
 	g.resetShipping()
 	np.beginningOfTurnReset()
 	g.setCurrentPlayers(np)
-	return s, nil, nil
+	return nil
 }
 
-func (g *Game) validateCompanyExpansionFinishTurn(c *gin.Context, cu *user.User) (*user.Stats, error) {
+func (g *Game) validateCompanyExpansionFinishTurn(cp *Player) error {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	com := g.SelectedCompany()
-	s, err := g.validateFinishTurn(c, cu)
+	err := g.validateFinishTurn(cp)
 	switch {
 	case err != nil:
-		return nil, err
+		return err
 	case g.Phase != Operations:
-		return nil, sn.NewVError("Expected %q phase but have %q phase.", Operations, g.PhaseName())
+		return sn.NewVError("Expected %q phase but have %q phase.", Operations, g.PhaseName())
 	case g.SubPhase != OPFreeExpansion && g.SubPhase != OPExpansion:
-		return nil, sn.NewVError("Expected an expansion subphase but have %q subphase.", g.SubPhaseName())
+		return sn.NewVError("Expected an expansion subphase but have %q subphase.", g.SubPhaseName())
 	case com == nil:
-		return nil, sn.NewVError("You must select a company to operate.")
+		return sn.NewVError("You must select a company to operate.")
 	case !com.Operated:
-		return nil, sn.NewVError("You must operate the selected company.")
+		return sn.NewVError("You must operate the selected company.")
 	default:
-		return s, nil
+		return nil
 	}
 }
 
-func (client *Client) cityGrowthFinishTurn(c *gin.Context, g *Game, cu *user.User) (*user.Stats, []*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) cityGrowthFinishTurn(cp *Player) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	s, err := g.validateCityGrowthFinishTurn(c, cu)
+	err := g.validateCityGrowthFinishTurn(cp)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
-	restful.AddNoticef(c, "%s finished turn.", g.NameFor(g.CurrentPlayer()))
-	cs, err := client.startNewEra(c, g)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return s, cs, err
+	g.startNewEra()
+	return nil
 }
 
-func (g *Game) validateCityGrowthFinishTurn(c *gin.Context, cu *user.User) (s *user.Stats, err error) {
+func (g *Game) validateCityGrowthFinishTurn(cp *Player) (err error) {
 	cmap := g.CityGrowthMap()
-	switch s, err = g.validateFinishTurn(c, cu); {
+	switch err = g.validateFinishTurn(cp); {
 	case err != nil:
 	case g.Phase != CityGrowth:
 		err = sn.NewVError("Expected %q phase but have %q phase.", CityGrowth, g.PhaseName())
@@ -1,7 +1,6 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"errors"
 	"html/template"
 	"math/rand"
@@ -17,12 +16,12 @@ import (
 )
 
 func init() {
-	gob.Register(new(setupEntry))
-	gob.Register(new(startEntry))
+	registerType(new(setupEntry))
+	registerType(new(startEntry))
 }
 
 func (client *Client) register(t gtype.Type) *Client {
-	gob.Register(new(Game))
+	registerType(new(Game))
 	game.Register(t, newGamer, PhaseNames, nil)
 	return client.addRoutes(t.Prefix())
 }
@@ -34,6 +33,9 @@ const NoPlayerID = game.NoPlayerID
 type Game struct {
 	*game.Header
 	*State
+
+	events Events
+	rng    *rand.Rand
 }
 
 type State struct {
@@ -47,6 +49,15 @@ type State struct {
 	SiapFajiMerger     *SiapFajiMerger
 	OverrideDeliveries int
 	Version            int
+	Seed               int64
+	Draws              int64
+	Journal            Journal
+	Journaled          bool
+	Recorded           int
+	BotSeats           []BotSeat
+	SealedBids         bool
+	SchemaVersion      int
+	Encoding           string
 	*TempData
 }
 
@@ -71,8 +82,9 @@ type TempData struct {
 	Expansions               int
 	RequiredExpansions       int
 	RequiredDeliveries       int
-	ProposedPath             flowMatrix
-	CustomPath               flowMatrix
+	ProposedPath             flowMatrix   `json:"-"`
+	AlternativePaths         []flowMatrix `json:"-"`
+	CustomPath               flowMatrix   `json:"-"`
 	ShipperIncomeMap         ShipperIncomeMap
 	Admin                    bool
 	AdminAction              string
@@ -169,14 +181,16 @@ func (g *Game) setPlayers(players Players) {
 
 type Games []*Game
 
-func (client *Client) Start(c *gin.Context, g *Game) error {
+// Start seats the players, deals the city cards, and begins the first era.
+func (g *Game) Start() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Status = game.Running
 	g.Version = 2
-	g.setupPhase(c)
-	return client.start(c, g)
+	g.Journaled = true
+	g.setupPhase()
+	g.start()
 }
 
 func (g *Game) addNewPlayers() {
@@ -185,7 +199,7 @@ func (g *Game) addNewPlayers() {
 	}
 }
 
-func (g *Game) setupPhase(c *gin.Context) {
+func (g *Game) setupPhase() {
 	g.Turn = 0
 	g.Phase = Setup
 	g.CityStones = []int{12, 8, 3}
@@ -232,14 +246,13 @@ func (e *setupEntry) HTML(c *gin.Context) template.HTML {
 	return restful.HTML("%s received 100 rupiah and 3 city cards.", g.NameByPID(e.PlayerID))
 }
 
-func (client *Client) start(c *gin.Context, g *Game) error {
+func (g *Game) start() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = StartGame
 	g.newStartEntry()
-	_, err := client.startNewEra(c, g)
-	return err
+	g.startNewEra()
 }
 
 type startEntry struct {
@@ -371,7 +384,7 @@ func (g *Game) adminHeader(c *gin.Context, cu *user.User) (string, game.ActionTy
 		Phase         game.Phase       `form:"phase" binding:"min=0"`
 		SubPhase      game.SubPhase    `form:"sub-phase" binding:"min=0"`
 		Round         int              `form:"round" binding:"min=0"`
-		NumPlayers    int              `form:"num-players" binding"min=0,max=5"`
+		NumPlayers    int              `form:"num-players" binding:"min=0,max=5"`
 		Password      string           `form:"password"`
 		CreatorID     int64            `form:"creator-id"`
 		CreatorSID    string           `form:"creator-sid"`
@@ -379,7 +392,7 @@ func (g *Game) adminHeader(c *gin.Context, cu *user.User) (string, game.ActionTy
 		UserIDS       []int64          `form:"user-ids"`
 		UserSIDS      []string         `form:"user-sids"`
 		UserNames     []string         `form:"user-names"`
-		UserEmails    []string         `form:"user-emails`
+		UserEmails    []string         `form:"user-emails"`
 		OrderIDS      game.UserIndices `form:"order-ids"`
 		CPUserIndices game.UserIndices `form:"cp-user-indices"`
 		WinnerIDS     game.UserIndices `form:"winner-ids"`
@@ -409,6 +422,7 @@ func (g *Game) adminHeader(c *gin.Context, cu *user.User) (string, game.ActionTy
 	g.CPUserIndices = h.CPUserIndices
 	g.WinnerIDS = h.WinnerIDS
 	g.Status = h.Status
+	g.editedOutsideJournal()
 	return "", game.Save, nil
 }
 
@@ -432,6 +446,7 @@ func (g *Game) adminCities(c *gin.Context, cu *user.User) (string, game.ActionTy
 	for i, s := range form.CityStones {
 		g.CityStones[i] = s
 	}
+	g.editedOutsideJournal()
 
 	// act = game.Save
 	return "", game.Save, nil
@@ -567,7 +582,7 @@ func max(ints ...int) int {
 }
 
 func (g *Game) RandomTurnOrder() {
-	rand.Shuffle(len(g.Playerers), func(i, j int) {
+	g.rand().Shuffle(len(g.Playerers), func(i, j int) {
 		g.Playerers[i], g.Playerers[j] = g.Playerers[j], g.Playerers[i]
 	})
 
@@ -0,0 +1,476 @@
+package indonesia
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+	"github.com/gin-gonic/gin"
+)
+
+// A game record is a plain text description of a whole game.
+//
+// It begins with tag lines of the form
+//
+//	[Name "value"]
+//
+// where value is a Go quoted string.  The recognized tags are
+//
+//	Format   version of the record format (currently 1)
+//	Game     title of the game
+//	Version  map version of the game (State.Version)
+//	Seed     seed of the random number generator
+//	Player   name of a player; one tag per player, in seat order
+//	Bot      "<seat> <strength>" for a seat played by a bot, such as "1 Expert"
+//	Variant  "sealed-bids" for games bidding for turn order with sealed bids
+//
+// The tags are followed by one action per line, in the order accepted:
+//
+//	<player> <action> [<argument> ...]
+//
+// where player is the seat index of the acting player and each argument is an integer.
+// The actions and their arguments are
+//
+//	place-city <area>
+//	play-card <card>
+//	turn-order-bid <rupiah>
+//	announce-merger <owner> <slot>
+//	announce-merger-partner <owner> <slot>
+//	merger-bid <rupiah>|none
+//	remove-rice-spice <area>
+//	remove-rice-spice-set <area> ...
+//	acquire-company <deed>
+//	place-initial-product <area>
+//	place-initial-ship <area>
+//	research <technology>
+//	select-hull-player <player>
+//	operate-company <slot>
+//	accept-proposed-flow
+//	accept-alternative-flow <plan>
+//	select-good <area>
+//	select-ship <area> <shipper>
+//	select-city <area>
+//	expand-production <area>
+//	expand-shipping <area>
+//	stop-expanding
+//	grow-cities <area> ...
+//	pass
+//	finish
+//
+// Blank lines and lines beginning with # are ignored.
+const recordFormat = 1
+
+// sealedBidsVariant tags the records of games bidding for turn order with sealed bids.
+const sealedBidsVariant = "sealed-bids"
+
+// Export writes the record of g to w.
+func (g *Game) Export(w io.Writer) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if !g.Journaled {
+		return sn.NewVError("Game was started before actions were journaled, or edited outside its journal, and can not be exported.")
+	}
+	if g.sealedBidsPending() {
+		return sn.NewVError("Game can not be exported while sealed bids are pending.")
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "[Format %q]\n", strconv.Itoa(recordFormat))
+	fmt.Fprintf(b, "[Game %q]\n", g.Title)
+	fmt.Fprintf(b, "[Version %q]\n", strconv.Itoa(g.Version))
+	fmt.Fprintf(b, "[Seed %q]\n", strconv.FormatInt(g.Seed, 10))
+	if g.SealedBids {
+		fmt.Fprintf(b, "[Variant %q]\n", sealedBidsVariant)
+	}
+	for _, name := range g.UserNames {
+		fmt.Fprintf(b, "[Player %q]\n", name)
+	}
+	for i, id := range g.UserIDS {
+		for _, seat := range g.BotSeats {
+			if seat.UserID == id {
+				fmt.Fprintf(b, "[Bot %q]\n", fmt.Sprintf("%d %s", i, seat.Strength))
+			}
+		}
+	}
+	fmt.Fprintln(b)
+
+	for i, r := range g.Journal {
+		if r.Version != journalVersion {
+			return fmt.Errorf("record %d has unsupported version %d", i, r.Version)
+		}
+		action, err := formatCommand(r.Command)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		fmt.Fprintf(b, "%d %s\n", r.PlayerID, action)
+	}
+	return b.Flush()
+}
+
+// Import reads a game record from r and replays it into a new game.
+// The players of the new game are given the user ids 1, 2, ... in seat order,
+// and the bots the user ids -1, -2, ... as if added to a recruiting game.
+func Import(r io.Reader) (*Game, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	g := New(nil, 0)
+	version := 2
+	var (
+		journal Journal
+		bots    []string
+	)
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			if len(journal) > 0 {
+				return nil, fmt.Errorf("line %d: tag follows actions", n)
+			}
+			name, value, err := parseTag(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			switch name {
+			case "Format":
+				if value != strconv.Itoa(recordFormat) {
+					return nil, fmt.Errorf("line %d: unsupported format %s", n, value)
+				}
+			case "Game":
+				g.Title = value
+			case "Version":
+				if version, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("line %d: invalid version: %v", n, err)
+				}
+			case "Seed":
+				if g.Seed, err = strconv.ParseInt(value, 10, 64); err != nil {
+					return nil, fmt.Errorf("line %d: invalid seed: %v", n, err)
+				}
+			case "Variant":
+				if value != sealedBidsVariant {
+					return nil, fmt.Errorf("line %d: unsupported variant %s", n, value)
+				}
+				g.SealedBids = true
+			case "Player":
+				g.UserNames = append(g.UserNames, value)
+				g.UserIDS = append(g.UserIDS, int64(len(g.UserNames)))
+			case "Bot":
+				bots = append(bots, value)
+			}
+		default:
+			pid, cmd, err := parseAction(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			journal = append(journal, &Record{Version: journalVersion, PlayerID: pid, Command: cmd})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case version != 2:
+		return nil, fmt.Errorf("unsupported map version %d", version)
+	case g.Seed == 0:
+		return nil, fmt.Errorf("missing seed")
+	case len(g.UserNames) < 2 || len(g.UserNames) > 5:
+		return nil, fmt.Errorf("record has %d players; 2 to 5 are required", len(g.UserNames))
+	}
+
+	for _, bot := range bots {
+		if err := g.importBot(bot); err != nil {
+			return nil, err
+		}
+	}
+
+	g.NumPlayers = len(g.UserNames)
+	g.Journal, g.Journaled = journal, true
+	if err := g.Replay(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// importBot gives the seat named by the value of a Bot tag to a bot.
+func (g *Game) importBot(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return fmt.Errorf("malformed bot %q", value)
+	}
+
+	seat, err := strconv.Atoi(fields[0])
+	if err != nil || seat < 0 || seat >= len(g.UserIDS) {
+		return fmt.Errorf("bot %q has no seat", value)
+	}
+	if g.UserIDS[seat] < 0 {
+		return fmt.Errorf("seat %d has several bots", seat)
+	}
+
+	for s, name := range strengthStrings {
+		if name == fields[1] {
+			id := -int64(len(g.BotSeats) + 1)
+			g.UserIDS[seat] = id
+			g.BotSeats = append(g.BotSeats, BotSeat{UserID: id, Strength: s})
+			return nil
+		}
+	}
+	return fmt.Errorf("bot %q has unknown strength", value)
+}
+
+// ImportGame imports the game record read from r as a new game of s, returning its key.
+// The users of the game are not linked to accounts, so it can be viewed but not played.
+func ImportGame(c context.Context, s GameStore, r io.Reader) (*datastore.Key, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	g, err := Import(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Key, err = s.AllocateID(c, newKey(nil, 0)); err != nil {
+		return nil, err
+	}
+
+	err = s.RunInTransaction(c, func(tx StoreTx) error {
+		return putStored(tx, g)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g.Key, nil
+}
+
+func parseTag(line string) (string, string, error) {
+	if !strings.HasSuffix(line, "]") {
+		return "", "", fmt.Errorf("malformed tag %s", line)
+	}
+	fields := strings.SplitN(strings.TrimSpace(line[1:len(line)-1]), " ", 2)
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("malformed tag %s", line)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return "", "", fmt.Errorf("malformed tag %s: %v", line, err)
+	}
+	return fields[0], value, nil
+}
+
+func formatCommand(cmd Command) (string, error) {
+	join := func(action string, args ...int) string {
+		for _, arg := range args {
+			action += " " + strconv.Itoa(arg)
+		}
+		return action
+	}
+
+	switch cmd := cmd.(type) {
+	case PlaceCity:
+		return join("place-city", int(cmd.Area)), nil
+	case PlayCard:
+		return join("play-card", cmd.Card), nil
+	case TurnOrderBid:
+		return join("turn-order-bid", cmd.Bid), nil
+	case AnnounceMerger:
+		return join("announce-merger", cmd.OwnerID, cmd.Slot), nil
+	case AnnounceMergerPartner:
+		return join("announce-merger-partner", cmd.OwnerID, cmd.Slot), nil
+	case MergerBid:
+		if cmd.Bid == NoBid {
+			return "merger-bid none", nil
+		}
+		return join("merger-bid", cmd.Bid), nil
+	case RemoveRiceSpice:
+		return join("remove-rice-spice", int(cmd.Area)), nil
+	case RemoveRiceSpiceSet:
+		ids := make([]int, len(cmd.Areas))
+		for i, id := range cmd.Areas {
+			ids[i] = int(id)
+		}
+		return join("remove-rice-spice-set", ids...), nil
+	case AcquireCompany:
+		return join("acquire-company", cmd.Deed), nil
+	case PlaceInitialProduct:
+		return join("place-initial-product", int(cmd.Area)), nil
+	case PlaceInitialShip:
+		return join("place-initial-ship", int(cmd.Area)), nil
+	case ConductResearch:
+		return join("research", int(cmd.Technology)), nil
+	case SelectHullPlayer:
+		return join("select-hull-player", cmd.PlayerID), nil
+	case OperateCompany:
+		return join("operate-company", cmd.Slot), nil
+	case AcceptProposedFlow:
+		return "accept-proposed-flow", nil
+	case AcceptAlternativeFlow:
+		return join("accept-alternative-flow", cmd.Plan), nil
+	case SelectGood:
+		return join("select-good", int(cmd.Area)), nil
+	case SelectShip:
+		return join("select-ship", int(cmd.Area), cmd.Shipper), nil
+	case SelectCity:
+		return join("select-city", int(cmd.Area)), nil
+	case ExpandProduction:
+		return join("expand-production", int(cmd.Area)), nil
+	case ExpandShipping:
+		return join("expand-shipping", int(cmd.Area)), nil
+	case StopExpanding:
+		return "stop-expanding", nil
+	case GrowCities:
+		ids := make([]int, len(cmd.Areas))
+		for i, id := range cmd.Areas {
+			ids[i] = int(id)
+		}
+		return join("grow-cities", ids...), nil
+	case Pass:
+		return "pass", nil
+	case FinishTurn:
+		return "finish", nil
+	default:
+		return "", fmt.Errorf("unknown command %T", cmd)
+	}
+}
+
+func parseAction(line string) (int, Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return NoPlayerID, nil, fmt.Errorf("malformed action %s", line)
+	}
+
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return NoPlayerID, nil, fmt.Errorf("invalid player %s", fields[0])
+	}
+
+	action, fs := fields[1], fields[2:]
+	if action == "merger-bid" && len(fs) == 1 && fs[0] == "none" {
+		return pid, MergerBid{Bid: NoBid}, nil
+	}
+
+	args := make([]int, len(fs))
+	for i, f := range fs {
+		if args[i], err = strconv.Atoi(f); err != nil {
+			return NoPlayerID, nil, fmt.Errorf("invalid argument %s", f)
+		}
+	}
+
+	want := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s takes %d arguments, got %d", action, n, len(args))
+		}
+		return nil
+	}
+
+	var cmd Command
+	switch action {
+	case "place-city":
+		err, cmd = want(1), PlaceCity{Area: area(args, 0)}
+	case "play-card":
+		err, cmd = want(1), PlayCard{Card: arg(args, 0)}
+	case "turn-order-bid":
+		err, cmd = want(1), TurnOrderBid{Bid: arg(args, 0)}
+	case "announce-merger":
+		err, cmd = want(2), AnnounceMerger{OwnerID: arg(args, 0), Slot: arg(args, 1)}
+	case "announce-merger-partner":
+		err, cmd = want(2), AnnounceMergerPartner{OwnerID: arg(args, 0), Slot: arg(args, 1)}
+	case "merger-bid":
+		err, cmd = want(1), MergerBid{Bid: arg(args, 0)}
+	case "remove-rice-spice":
+		err, cmd = want(1), RemoveRiceSpice{Area: area(args, 0)}
+	case "remove-rice-spice-set":
+		ids := make(AreaIDS, len(args))
+		for i := range args {
+			ids[i] = area(args, i)
+		}
+		cmd = RemoveRiceSpiceSet{Areas: ids}
+	case "acquire-company":
+		err, cmd = want(1), AcquireCompany{Deed: arg(args, 0)}
+	case "place-initial-product":
+		err, cmd = want(1), PlaceInitialProduct{Area: area(args, 0)}
+	case "place-initial-ship":
+		err, cmd = want(1), PlaceInitialShip{Area: area(args, 0)}
+	case "research":
+		err, cmd = want(1), ConductResearch{Technology: Technology(arg(args, 0))}
+	case "select-hull-player":
+		err, cmd = want(1), SelectHullPlayer{PlayerID: arg(args, 0)}
+	case "operate-company":
+		err, cmd = want(1), OperateCompany{Slot: arg(args, 0)}
+	case "accept-proposed-flow":
+		err, cmd = want(0), AcceptProposedFlow{}
+	case "accept-alternative-flow":
+		err, cmd = want(1), AcceptAlternativeFlow{Plan: arg(args, 0)}
+	case "select-good":
+		err, cmd = want(1), SelectGood{Area: area(args, 0)}
+	case "select-ship":
+		err, cmd = want(2), SelectShip{Area: area(args, 0), Shipper: arg(args, 1)}
+	case "select-city":
+		err, cmd = want(1), SelectCity{Area: area(args, 0)}
+	case "expand-production":
+		err, cmd = want(1), ExpandProduction{Area: area(args, 0)}
+	case "expand-shipping":
+		err, cmd = want(1), ExpandShipping{Area: area(args, 0)}
+	case "stop-expanding":
+		err, cmd = want(0), StopExpanding{}
+	case "grow-cities":
+		ids := make(AreaIDS, len(args))
+		for i := range args {
+			ids[i] = area(args, i)
+		}
+		cmd = GrowCities{Areas: ids}
+	case "pass":
+		err, cmd = want(0), Pass{}
+	case "finish":
+		err, cmd = want(0), FinishTurn{}
+	default:
+		err = fmt.Errorf("unknown action %s", action)
+	}
+	if err != nil {
+		return NoPlayerID, nil, err
+	}
+	return pid, cmd, nil
+}
+
+// arg returns args[i], or zero if args is too short; arity is checked separately.
+func arg(args []int, i int) int {
+	if i < len(args) {
+		return args[i]
+	}
+	return 0
+}
+
+func area(args []int, i int) AreaID {
+	return AreaID(arg(args, i))
+}
+
+func (client *Client) exportRecord(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g := gameFrom(c)
+	if g == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := g.Export(&b); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=indonesia-%d.txt", g.ID()))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", b.Bytes())
+}
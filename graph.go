@@ -0,0 +1,262 @@
+package indonesia
+
+import "math/bits"
+
+// numAreaIDs bounds the area ids of both map versions.
+const numAreaIDs = int(JawaTengah137) + 1
+
+// areaSet is a set of areas held as a bitset indexed by area id.
+type areaSet [(numAreaIDs + 63) / 64]uint64
+
+func setOf(ids ...AreaID) (s areaSet) {
+	for _, id := range ids {
+		s.add(id)
+	}
+	return
+}
+
+func (s *areaSet) add(id AreaID) {
+	if id >= 0 && int(id) < numAreaIDs {
+		s[id/64] |= 1 << uint(id%64)
+	}
+}
+
+func (s *areaSet) remove(id AreaID) {
+	if id >= 0 && int(id) < numAreaIDs {
+		s[id/64] &^= 1 << uint(id%64)
+	}
+}
+
+func (s areaSet) has(id AreaID) bool {
+	return id >= 0 && int(id) < numAreaIDs && s[id/64]&(1<<uint(id%64)) != 0
+}
+
+func (s areaSet) union(t areaSet) areaSet {
+	for i := range s {
+		s[i] |= t[i]
+	}
+	return s
+}
+
+func (s areaSet) intersect(t areaSet) areaSet {
+	for i := range s {
+		s[i] &= t[i]
+	}
+	return s
+}
+
+func (s areaSet) minus(t areaSet) areaSet {
+	for i := range s {
+		s[i] &^= t[i]
+	}
+	return s
+}
+
+func (s areaSet) intersects(t areaSet) bool {
+	return !s.intersect(t).empty()
+}
+
+func (s areaSet) empty() bool {
+	return s == areaSet{}
+}
+
+// ids returns the areas of s in increasing order of id.
+func (s areaSet) ids() AreaIDS {
+	var ids AreaIDS
+	for i, word := range s {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			ids = append(ids, AreaID(i*64+bit))
+			word &= word - 1
+		}
+	}
+	return ids
+}
+
+// landIDS2 lists the land areas of map version 2.
+// Its capacity is its length, so appending to it copies.
+var landIDS2 = append(landIDS[:len(landIDS):len(landIDS)], JawaTengah136, JawaTengah137)
+
+var (
+	seaSet   = setOf(seaIDS...)
+	landSet  = setOf(landIDS...)
+	landSet2 = setOf(landIDS2...)
+)
+
+// adjacency holds the areas adjacent to each area, for the original map and for map version 2.
+var adjacency [2][numAreaIDs]areaSet
+
+func init() {
+	for i, version := range []int{1, 2} {
+		for id := 0; id < numAreaIDs; id++ {
+			adjacency[i][id] = setOf(adjacentAreaIDSFor(version, AreaID(id))...)
+		}
+	}
+}
+
+// neighbors returns the areas adjacent to the area having id aid.
+func (g *Game) neighbors(aid AreaID) areaSet {
+	if aid < 0 || int(aid) >= numAreaIDs {
+		return areaSet{}
+	}
+	if g.Version == 2 {
+		return adjacency[1][aid]
+	}
+	return adjacency[0][aid]
+}
+
+// neighborhood returns the areas adjacent to any area of s.
+func (g *Game) neighborhood(s areaSet) areaSet {
+	var adjacent areaSet
+	for _, id := range s.ids() {
+		adjacent = adjacent.union(g.neighbors(id))
+	}
+	return adjacent
+}
+
+// components splits s into its parts of adjacent areas, ordered by their least area id.
+func (g *Game) components(s areaSet) []areaSet {
+	var parts []areaSet
+	for left := s; !left.empty(); {
+		reached := setOf(left.ids()[0])
+		for frontier := reached; !frontier.empty(); reached = reached.union(frontier) {
+			frontier = g.neighborhood(frontier).intersect(left).minus(reached)
+		}
+		parts = append(parts, reached)
+		left = left.minus(reached)
+	}
+	return parts
+}
+
+// flowGraph is a flowNetwork compiled to arrays.  Nodes are numbered from
+// zero, the source being node 0 and the target node 1.  Each edge is stored
+// beside its reverse, edge e^1, so augmenting a path only updates the residual
+// capacities of its edges.
+type flowGraph struct {
+	nodes []FlowID
+	index map[FlowID]int
+	edges [][]int
+	to    []int
+	cap   []int
+	cost  []int
+}
+
+const (
+	sourceNode = 0
+	targetNode = 1
+)
+
+// compile builds the part of n reachable from the source.
+func (n *flowNetwork) compile() *flowGraph {
+	fg := &flowGraph{index: make(map[FlowID]int, 0)}
+	fg.node(sourceFID)
+	fg.node(targetFID)
+	for u := 0; u < len(fg.nodes); u++ {
+		from := fg.nodes[u]
+		for _, to := range n.neighboringFIDSFor(from) {
+			if capacity := n.capBetween(from, to); capacity > 0 {
+				fg.addEdge(u, fg.node(to), capacity, n.costBetween(from, to))
+			}
+		}
+	}
+	return fg
+}
+
+func (fg *flowGraph) node(fid FlowID) int {
+	if u, ok := fg.index[fid]; ok {
+		return u
+	}
+	u := len(fg.nodes)
+	fg.index[fid] = u
+	fg.nodes = append(fg.nodes, fid)
+	fg.edges = append(fg.edges, nil)
+	return u
+}
+
+func (fg *flowGraph) addEdge(u, v, capacity, cost int) {
+	e := len(fg.to)
+	fg.to = append(fg.to, v, u)
+	fg.cap = append(fg.cap, capacity, 0)
+	fg.cost = append(fg.cost, cost, -cost)
+	fg.edges[u] = append(fg.edges[u], e)
+	fg.edges[v] = append(fg.edges[v], e+1)
+}
+
+// Successive shortest paths
+//
+// Each augmenting path is the cheapest in the residual graph, so the flow
+// after each augmentation is the cheapest of its size and the final flow is
+// the cheapest maximum flow.  Residual costs can be negative, so paths are
+// found by Bellman-Ford with a work queue.  Negative cycles can not arise,
+// since every flow augmented so far is the cheapest of its size.
+func (fg *flowGraph) minCostFlow() int {
+	const unreached = int(^uint(0) >> 1)
+
+	size := len(fg.nodes)
+	costTo := make([]int, size)
+	via := make([]int, size)
+	queued := make([]bool, size)
+	queue := make([]int, 0, size)
+
+	flow := 0
+	for {
+		for u := range costTo {
+			costTo[u], via[u] = unreached, -1
+		}
+		costTo[sourceNode], queued[sourceNode] = 0, true
+		queue = append(queue[:0], sourceNode)
+		for head := 0; head < len(queue); head++ {
+			u := queue[head]
+			queued[u] = false
+			for _, e := range fg.edges[u] {
+				if fg.cap[e] <= 0 {
+					continue
+				}
+				v, cost := fg.to[e], costTo[u]+fg.cost[e]
+				if cost >= costTo[v] {
+					continue
+				}
+				costTo[v], via[v] = cost, e
+				if !queued[v] {
+					queued[v] = true
+					queue = append(queue, v)
+				}
+			}
+		}
+
+		if costTo[targetNode] == unreached {
+			return flow
+		}
+
+		push := infinity
+		for v := targetNode; v != sourceNode; v = fg.to[via[v]^1] {
+			push = min(push, fg.cap[via[v]])
+		}
+		for v := targetNode; v != sourceNode; v = fg.to[via[v]^1] {
+			fg.cap[via[v]] -= push
+			fg.cap[via[v]^1] += push
+		}
+		flow += push
+	}
+}
+
+// flows returns the flow along each edge, in the form recorded by the game.
+func (fg *flowGraph) flows() flowMatrix {
+	fm := make(flowMatrix, 0)
+	for e := 0; e < len(fg.to); e += 2 {
+		flow := fg.cap[e+1]
+		if flow <= 0 {
+			continue
+		}
+		u, v := fg.nodes[fg.to[e+1]], fg.nodes[fg.to[e]]
+		if _, ok := fm[u]; !ok {
+			fm[u] = make(subflow, 0)
+		}
+		if _, ok := fm[v]; !ok {
+			fm[v] = make(subflow, 0)
+		}
+		fm[u][v] += flow
+		fm[v][u] -= flow
+	}
+	return fm
+}
@@ -0,0 +1,22 @@
+package indonesia
+
+import "fmt"
+
+// NewHeadless returns a started game for the named players that is played
+// entirely in memory, without a datastore or web client.
+// A zero seed is replaced by one drawn from the clock.
+func NewHeadless(seed int64, names ...string) (*Game, error) {
+	if l := len(names); l < 2 || l > 5 {
+		return nil, fmt.Errorf("game has %d players; 2 to 5 are required", l)
+	}
+
+	g := New(nil, 0)
+	for i, name := range names {
+		g.UserNames = append(g.UserNames, name)
+		g.UserIDS = append(g.UserIDS, int64(i+1))
+	}
+	g.NumPlayers = len(names)
+	g.Seed = seed
+	g.Start()
+	return g, nil
+}
@@ -0,0 +1,253 @@
+package indonesia
+
+// HeuristicBot is a Bot that scores each of its botActions with simple rules
+// of thumb and chooses the best.  Ties go to the action listed first, so the
+// bot plays deterministically.
+type HeuristicBot struct{}
+
+// Choose implements Bot.
+func (HeuristicBot) Choose(g *Game, pid int) Command {
+	p := g.PlayerByID(pid)
+	var best Command
+	bestScore := 0
+	for _, cmd := range botActions(g, pid) {
+		if score := scoreAction(g, p, cmd); best == nil || score > bestScore {
+			best, bestScore = cmd, score
+		}
+	}
+	return best
+}
+
+// scoreAction rates cmd for p.  Finishing a turn or passing scores zero,
+// so actions with a negative score are only taken when nothing else is legal.
+func scoreAction(g *Game, p *Player, cmd Command) int {
+	switch cmd := cmd.(type) {
+	case PlayCard:
+		return 1
+	case PlaceCity:
+		return scoreCityArea(g.GetArea(cmd.Area))
+	case TurnOrderBid:
+		return -abs(cmd.Bid - turnOrderBidFor(p))
+	case AnnounceMerger:
+		return scoreAnnounceMerger(g, p, cmd)
+	case AnnounceMergerPartner:
+		return scoreMergerPartner(g, p, cmd)
+	case MergerBid:
+		return scoreMergerBid(g, p, cmd.Bid)
+	case RemoveRiceSpice:
+		return -len(g.GetArea(cmd.Area).AdjacentCityAreas())
+	case RemoveRiceSpiceSet:
+		return 1
+	case AcquireCompany:
+		return scoreDeed(g, g.AvailableDeeds[cmd.Deed])
+	case PlaceInitialProduct:
+		return scoreProductionArea(g.GetArea(cmd.Area))
+	case PlaceInitialShip, ExpandShipping:
+		return scoreShippingArea(g, cmd)
+	case ConductResearch:
+		return scoreResearch(g, p, cmd.Technology)
+	case SelectHullPlayer:
+		if cmd.PlayerID == p.ID() {
+			return 1
+		}
+		return 0
+	case OperateCompany:
+		return 1 + size(p.Slots[cmd.Slot-1].Company)
+	case AcceptProposedFlow:
+		return 100
+	case AcceptAlternativeFlow:
+		return 99
+	case SelectGood, SelectShip, SelectCity:
+		return -1
+	case ExpandProduction:
+		return scoreExpandProduction(g, p, cmd)
+	case GrowCities:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// turnOrderBidFor returns the bid with which p expects to outbid its rivals,
+// or zero if doing so would cost more than a tenth of its rupiah.
+// A higher Multiplier makes each rupiah of the bid worth more.
+func turnOrderBidFor(p *Player) int {
+	rival := 0
+	for _, op := range p.Game().Players() {
+		if op.ID() != p.ID() && op.Rupiah > 0 {
+			if total := op.Rupiah / 20 * op.Multiplier(); total > rival {
+				rival = total
+			}
+		}
+	}
+
+	bid := rival/p.Multiplier() + 1
+	if bid > p.Rupiah/10 {
+		return 0
+	}
+	return bid
+}
+
+// mergerValue estimates the worth of merger m to p as two rounds of the income
+// of the merged company, plus the share p receives as the owner of a merged company.
+func mergerValue(p *Player, m *Merger, bid int) int {
+	value, inc := 2*m.NominalBid(), m.BidIncrement()
+	if inc <= 0 {
+		return value
+	}
+	if m.Owner1ID == p.ID() {
+		value += size(m.Company1()) * bid / inc
+	}
+	if m.Owner2ID == p.ID() {
+		value += size(m.Company2()) * bid / inc
+	}
+	return value
+}
+
+// size returns the ships of a shipping company and the production of any other.
+func size(c *Company) int {
+	if c.IsShippingCompany() {
+		return c.Ships()
+	}
+	return c.Production()
+}
+
+func scoreMergerBid(g *Game, p *Player, bid int) int {
+	m := g.Merger
+	switch {
+	case bid == NoBid:
+		return 0
+	case bid > p.Rupiah*2/3 && m.CurrentBid != 0:
+		return -1
+	default:
+		return mergerValue(p, m, bid) - bid
+	}
+}
+
+// scoreAnnounceMerger rates announcing a merger of the company in cmd with its best partner.
+func scoreAnnounceMerger(g *Game, p *Player, cmd AnnounceMerger) int {
+	c1 := g.PlayerByID(cmd.OwnerID).Slots[cmd.Slot-1].Company
+	best := 0
+	for _, c2 := range mergeableCompaniesFor(p)[c1] {
+		if score := scoreMerger(p, c1, c2); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+func scoreMergerPartner(g *Game, p *Player, cmd AnnounceMergerPartner) int {
+	c2 := g.PlayerByID(cmd.OwnerID).Slots[cmd.Slot-1].Company
+	return scoreMerger(p, g.Merger.Company1(), c2)
+}
+
+// scoreMerger rates announcing a merger of c1 and c2, which p must open with the nominal bid.
+func scoreMerger(p *Player, c1, c2 *Company) int {
+	m := &Merger{g: p.Game()}
+	m.setCompany1(c1)
+	m.setCompany2(c2)
+	nominal := m.NominalBid()
+	if nominal <= 0 || nominal > p.Rupiah/2 {
+		return -1
+	}
+	return mergerValue(p, m, nominal) - nominal
+}
+
+// scoreDeed prefers valuable goods in provinces near cities and ships,
+// and shipping when too few ships are in play.
+func scoreDeed(g *Game, d *Deed) int {
+	if d.Goods == Shipping {
+		score := 10 * d.MaxShips[g.Era]
+		if len(g.ShippingCompanies()) < len(g.Players()) {
+			score *= 2
+		}
+		return score
+	}
+
+	score := 3 * d.Goods.Price()
+	for _, a := range g.areasInProvince(d.Province) {
+		score += 5 * len(a.AdjacentCityAreas())
+		for _, sea := range a.AdjacentSeaAreas() {
+			if sea.hasAShipper() {
+				score += 10
+			}
+		}
+	}
+	return score
+}
+
+// scoreExpandProduction always takes free expansions, but only pays to expand
+// a company that delivered all of its goods, while p can easily afford it.
+func scoreExpandProduction(g *Game, p *Player, cmd ExpandProduction) int {
+	c := g.SelectedCompany()
+	if g.SubPhase == OPExpansion && (!c.deliveredAllGoods() || p.Rupiah < 2*c.Goods().Price()) {
+		return -1
+	}
+	return 10 + scoreProductionArea(g.GetArea(cmd.Area))
+}
+
+// scoreCityArea prefers areas with room for nearby producers and ships.
+func scoreCityArea(a *Area) int {
+	return len(a.AdjacentLandAreas()) + len(a.AdjacentSeaAreas())
+}
+
+// scoreProductionArea prefers areas near cities and the sea.
+func scoreProductionArea(a *Area) int {
+	return 3*len(a.AdjacentCityAreas()) + len(a.AdjacentSeaAreas())
+}
+
+// scoreShippingArea prefers sea areas next to producers and cities.
+func scoreShippingArea(g *Game, cmd Command) int {
+	var a *Area
+	switch cmd := cmd.(type) {
+	case PlaceInitialShip:
+		a = g.GetArea(cmd.Area)
+	case ExpandShipping:
+		a = g.GetArea(cmd.Area)
+	}
+
+	score := 1
+	for _, land := range a.AdjacentLandAreas() {
+		switch {
+		case land.hasProducer():
+			score += 3
+		case land.hasCity():
+			score += 2
+		}
+	}
+	if _, ok := cmd.(ExpandShipping); ok {
+		score += 10
+	}
+	return score
+}
+
+// scoreResearch favours slots while every slot is full, then expansions,
+// and mergers once there are companies worth merging.
+func scoreResearch(g *Game, p *Player, t Technology) int {
+	level := p.Technologies[t]
+	switch t {
+	case SlotsTech:
+		if !p.hasEmptySlot() {
+			return 20 - level
+		}
+		return 5 - level
+	case ExpansionsTech:
+		return 12 - level
+	case MergersTech:
+		if len(g.Companies()) > len(g.Players()) {
+			return 10 - level
+		}
+		return 4 - level
+	case HullTech:
+		return 8 - level
+	default:
+		return 6 - level
+	}
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
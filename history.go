@@ -0,0 +1,270 @@
+package indonesia
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SlothNinja/color"
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/restful"
+	"github.com/SlothNinja/sn"
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+)
+
+// Checkpoints returns the journal positions at which the game can be viewed:
+// the start of the game, the end of each finished turn and, if the journal
+// goes on, its end.  Checkpoint n is the game with the first Checkpoints()[n]
+// records replayed.
+func (g *Game) Checkpoints() []int {
+	cps := []int{0}
+	for i, r := range g.Journal {
+		if _, ok := r.Command.(FinishTurn); ok {
+			cps = append(cps, i+1)
+		}
+	}
+	if l := len(g.Journal); cps[len(cps)-1] != l {
+		cps = append(cps, l)
+	}
+	return cps
+}
+
+// At returns the game as it stood at checkpoint n, rebuilt by replaying the journal.
+func (g *Game) At(n int) (*Game, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if !g.Journaled {
+		return nil, sn.NewVError("Game was started before actions were journaled, or edited outside its journal, and has no history.")
+	}
+
+	cps := g.Checkpoints()
+	if n < 0 || n >= len(cps) {
+		return nil, sn.NewVError("Game has no checkpoint %d.  Checkpoints run from 0 to %d.", n, len(cps)-1)
+	}
+
+	past, err := g.Clone()
+	if err != nil {
+		return nil, err
+	}
+	past.Journal = past.Journal[:cps[n]]
+	if err := past.Replay(); err != nil {
+		return nil, err
+	}
+	past.viewOf(g)
+	return past, nil
+}
+
+// viewOf copies the fields of the header of g describing the game and its users,
+// so past may be shown in place of g.
+func (past *Game) viewOf(g *Game) {
+	past.Key, past.Type = g.Key, g.Type
+	past.Creator, past.Users = g.Creator, g.Users
+	past.CreatorID, past.CreatorKey, past.CreatorSID = g.CreatorID, g.CreatorKey, g.CreatorSID
+	past.CreatorName, past.CreatorEmailHash, past.CreatorGravType = g.CreatorName, g.CreatorEmailHash, g.CreatorGravType
+	past.UserKeys, past.UserSIDS = g.UserKeys, g.UserSIDS
+	past.UserEmailHashes, past.UserGravTypes = g.UserEmailHashes, g.UserGravTypes
+	past.Options, past.OptString = g.Options, g.OptString
+	past.CreatedAt, past.StartedAt = g.CreatedAt, g.StartedAt
+	past.SetCTX(g.CTX())
+}
+
+// History locates a past view of a game among its checkpoints, with the paths
+// stepping through them.  Prev and Next are empty at the first and last checkpoint.
+type History struct {
+	At     int    `json:"at"`
+	Last   int    `json:"last"`
+	Prev   string `json:"prev,omitempty"`
+	Next   string `json:"next,omitempty"`
+	Latest string `json:"latest"`
+}
+
+func newHistory(prefix, hid string, n, last int) *History {
+	h := &History{At: n, Last: last, Latest: showPath(prefix, hid)}
+	if n > 0 {
+		h.Prev = historyPath(prefix, hid, n-1)
+	}
+	if n < last {
+		h.Next = historyPath(prefix, hid, n+1)
+	}
+	return h
+}
+
+func historyPath(prefix, hid string, n int) string {
+	return fmt.Sprintf("%s/at/%d", showPath(prefix, hid), n)
+}
+
+// links sets the Link header of the response to the paths stepping from h.
+func (h *History) links(c *gin.Context) {
+	links := []string{fmt.Sprintf("<%s>; rel=\"last\"", h.Latest)}
+	if h.Prev != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", h.Prev))
+	}
+	if h.Next != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", h.Next))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// historyOf returns the history of g at its latest checkpoint, or nil if g has no history.
+func historyOf(prefix, hid string, g *Game) *History {
+	if g == nil || !g.Journaled {
+		return nil
+	}
+	last := len(g.Checkpoints()) - 1
+	return newHistory(prefix, hid, last, last)
+}
+
+// pastGame returns g at checkpoint n.  A replay depends only on the journal
+// records replayed, so the states at checkpoints are cached encoded under the
+// game and their count.  Each request decodes its own copy of a state, and a
+// miss replays onward from the latest cached checkpoint before n.
+func (client *Client) pastGame(g *Game, n int) (*Game, error) {
+	cps := g.Checkpoints()
+	if !g.Journaled || n < 0 || n >= len(cps) {
+		return g.At(n)
+	}
+
+	var past *Game
+	k := n
+	for ; k >= 0; k-- {
+		if past = client.cachedCheckpoint(g, cps[k]); past != nil {
+			break
+		}
+	}
+
+	if past == nil {
+		k = 0
+		var err error
+		if past, err = g.At(0); err != nil {
+			return nil, err
+		}
+		if err := client.cacheCheckpoint(g, past); err != nil {
+			return nil, err
+		}
+	}
+
+	for ; k < n; k++ {
+		if err := past.replayRecords(g.Journal[cps[k]:cps[k+1]], cps[k]); err != nil {
+			return nil, err
+		}
+		if err := client.cacheCheckpoint(g, past); err != nil {
+			return nil, err
+		}
+	}
+	past.viewOf(g)
+	return past, nil
+}
+
+func checkpointKey(g *Game, records int) string {
+	return fmt.Sprintf("%s-history-%d", g.Key.Encode(), records)
+}
+
+// cacheCheckpoint caches the encoded state of past, g at one of its checkpoints.
+func (client *Client) cacheCheckpoint(g, past *Game) error {
+	data, err := past.encodeTurn()
+	if err != nil {
+		return err
+	}
+	client.Cache.Set(checkpointKey(g, len(past.Journal)), data, cache.DefaultExpiration)
+	return nil
+}
+
+// cachedCheckpoint returns a copy of g with its first records replayed, decoded
+// from the state cached by cacheCheckpoint, or nil if the state is not cached.
+func (client *Client) cachedCheckpoint(g *Game, records int) *Game {
+	item, found := client.Cache.Get(checkpointKey(g, records))
+	if !found {
+		return nil
+	}
+	data, ok := item.([]byte)
+	if !ok {
+		return nil
+	}
+
+	past := New(nil, 0)
+	if err := past.decodeTurn(data); err != nil {
+		client.Log.Warningf(err.Error())
+		return nil
+	}
+	past.initState()
+	return past
+}
+
+// history shows the game as it stood at the checkpoint given by the path, in
+// the layout of the show page.  With json set, the game and its history are
+// sent as JSON.
+func (client *Client) history(prefix string, json bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client.Log.Debugf(msgEnter)
+		defer client.Log.Debugf(msgExit)
+
+		hid := c.Param(hParam)
+		g := gameFrom(c)
+		if g == nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		n, err := strconv.Atoi(c.Param("n"))
+		if err != nil {
+			err = sn.NewVError("Received invalid checkpoint.")
+		}
+
+		var past *Game
+		if err == nil {
+			past, err = client.pastGame(g, n)
+		}
+		if err != nil {
+			if json {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			restful.AddErrorf(c, err.Error())
+			c.Redirect(http.StatusSeeOther, showPath(prefix, hid))
+			return
+		}
+
+		history := newHistory(prefix, hid, n, len(g.Checkpoints())-1)
+		history.links(c)
+		withGame(c, past)
+		if json {
+			c.JSON(http.StatusOK, gin.H{"history": history, "game": past})
+			return
+		}
+
+		id, err := getID(c)
+		if err != nil {
+			client.Log.Errorf(err.Error())
+			return
+		}
+
+		ml, err := client.getMLog(c, id)
+		if err != nil {
+			client.Log.Errorf(err.Error())
+			return
+		}
+
+		cu, err := client.User.Current(c)
+		if err != nil {
+			client.Log.Debugf(err.Error())
+		}
+
+		c.HTML(http.StatusOK, prefix+"/show", gin.H{
+			"Context":    c,
+			"VersionID":  sn.VersionID(),
+			"CUser":      cu,
+			"Game":       past,
+			"History":    history,
+			"IsAdmin":    cu.IsAdmin(),
+			"Admin":      game.AdminFrom(c),
+			"MessageLog": ml,
+			"ColorMap":   color.MapFrom(c),
+			"Notices":    restful.NoticesFrom(c),
+			"Errors":     restful.ErrorsFrom(c),
+		})
+	}
+}
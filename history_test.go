@@ -0,0 +1,113 @@
+package indonesia
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+	"github.com/patrickmn/go-cache"
+)
+
+// TestPastGame checks that the views of a game built from cached checkpoints
+// are the views replayed from the start, and that each is a game of its own.
+func TestPastGame(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(1, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Key = GameKey(1)
+	for i := 0; i < 150; i++ {
+		pid := g.CPUserIndices[0]
+		if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	last := len(g.Checkpoints()) - 1
+	if last < 4 {
+		t.Fatalf("game has %d checkpoints, want at least 5", last+1)
+	}
+	client := &Client{Client: &sn.Client{Cache: cache.New(cache.NoExpiration, 0)}}
+
+	// Jump into the middle, step back to the start, then step forward past the cached checkpoints.
+	ns := []int{last / 2, last/2 - 1, 0}
+	for n := 1; n <= last; n++ {
+		ns = append(ns, n)
+	}
+	for _, n := range ns {
+		want, err := g.At(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantJSON := stateWithoutLog(t, want)
+
+		past, err := client.pastGame(g, n)
+		if err != nil {
+			t.Fatalf("checkpoint %d: %v", n, err)
+		}
+		again, err := client.pastGame(g, n)
+		if err != nil {
+			t.Fatalf("checkpoint %d: %v", n, err)
+		}
+		if past == again {
+			t.Errorf("checkpoint %d: requests share a game", n)
+		}
+
+		for _, got := range []*Game{past, again} {
+			if !bytes.Equal(stateWithoutLog(t, got), wantJSON) || len(got.Log) != len(want.Log) {
+				t.Errorf("checkpoint %d: cached view differs from the replayed view", n)
+			}
+			if got.Turn != want.Turn || got.Phase != want.Phase || !got.Key.Equal(g.Key) {
+				t.Errorf("checkpoint %d: view at turn %d, phase %d, key %v, want %d, %d, %v",
+					n, got.Turn, got.Phase, got.Key, want.Turn, want.Phase, g.Key)
+			}
+		}
+	}
+
+	if client.Cache.ItemCount() != last+1 {
+		t.Errorf("cached %d checkpoints, want %d", client.Cache.ItemCount(), last+1)
+	}
+	if _, err := client.pastGame(g, last+1); err == nil {
+		t.Errorf("viewing checkpoint %d succeeded, want an error", last+1)
+	}
+}
+
+// entryTime matches the times at which log entries were made, which differ between replays.
+var entryTime = regexp.MustCompile(`"CreatedAtF": "[^"]*"`)
+
+// stateWithoutLog returns the state of g in JSON without its log and the times
+// of its entries.  Log entries hold areas, which a replay shares with the game
+// and a decoded state copies, so the logs of equal games may differ.
+func stateWithoutLog(t *testing.T, g *Game) []byte {
+	t.Helper()
+	l := g.Log
+	g.Log = nil
+	data, err := g.StateJSON()
+	g.Log = l
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entryTime.ReplaceAll(data, nil)
+}
+
+func TestHistoryLinks(t *testing.T) {
+	tests := []struct {
+		n, last int
+		prev    string
+		next    string
+	}{
+		{0, 2, "", "/indonesia/game/show/1/at/1"},
+		{1, 2, "/indonesia/game/show/1/at/0", "/indonesia/game/show/1/at/2"},
+		{2, 2, "/indonesia/game/show/1/at/1", ""},
+	}
+	for _, test := range tests {
+		h := newHistory("indonesia", "1", test.n, test.last)
+		if h.Prev != test.prev || h.Next != test.next || h.Latest != "/indonesia/game/show/1" {
+			t.Errorf("newHistory(%d, %d) = %+v, want prev %q and next %q", test.n, test.last, h, test.prev, test.next)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package indonesia
+
+import (
+	"fmt"
+
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+	"github.com/SlothNinja/user"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerType(PlaceCity{})
+	registerType(PlayCard{})
+	registerType(TurnOrderBid{})
+	registerType(AnnounceMerger{})
+	registerType(AnnounceMergerPartner{})
+	registerType(MergerBid{})
+	registerType(RemoveRiceSpice{})
+	registerType(RemoveRiceSpiceSet{})
+	registerType(AcquireCompany{})
+	registerType(PlaceInitialProduct{})
+	registerType(PlaceInitialShip{})
+	registerType(ConductResearch{})
+	registerType(SelectHullPlayer{})
+	registerType(OperateCompany{})
+	registerType(AcceptProposedFlow{})
+	registerType(AcceptAlternativeFlow{})
+	registerType(SelectGood{})
+	registerType(SelectShip{})
+	registerType(SelectCity{})
+	registerType(ExpandProduction{})
+	registerType(ExpandShipping{})
+	registerType(StopExpanding{})
+	registerType(GrowCities{})
+	registerType(Pass{})
+	registerType(FinishTurn{})
+}
+
+// journalVersion is the version of newly journaled records.
+// Bump it whenever the meaning of a recorded command changes.
+const journalVersion = 1
+
+// Record is an accepted command together with the player that issued it.
+type Record struct {
+	Version  int
+	PlayerID int
+	Command  Command
+}
+
+// Journal lists, in order, every command accepted since setup.
+type Journal []*Record
+
+func (g *Game) record(pid int, cmd Command) {
+	g.Journal = append(g.Journal, &Record{Version: journalVersion, PlayerID: pid, Command: cmd})
+}
+
+// editedOutsideJournal marks g as changed by an edit its journal does not
+// record, such as an admin edit.  Replaying the journal would undo the edit,
+// so g is no longer replayed, exported or viewed at past turns.
+func (g *Game) editedOutsideJournal() {
+	g.Journaled = false
+}
+
+// Replay rebuilds the state of the game by running setup with the persisted seed
+// and reapplying every journaled command.
+// Admin edits are not journaled and are therefore not reproduced.
+// If an error is returned, the game is partially rebuilt and should be discarded.
+func (g *Game) Replay() error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if !g.Journaled {
+		return sn.NewVError("Game was started before actions were journaled, or edited outside its journal, and can not be replayed.")
+	}
+
+	journal, seed, sealed, bots, encoding := g.Journal, g.Seed, g.SealedBids, g.BotSeats, g.Encoding
+	g.Turn, g.Round = 0, 0
+	g.Phase, g.SubPhase = NoPhase, NoSubPhase
+	g.OrderIDS, g.CPUserIndices, g.WinnerIDS = nil, nil, nil
+	g.State = newState()
+	g.Seed, g.SealedBids, g.BotSeats = seed, sealed, bots
+	g.SchemaVersion, g.Encoding = CurrentSchemaVersion, encoding
+	g.rng = nil
+	g.Start()
+	return g.replayRecords(journal, 0)
+}
+
+// replayRecords reapplies rs, the records of a journal from record first on, to g.
+func (g *Game) replayRecords(rs Journal, first int) error {
+	for i, r := range rs {
+		if r.Version > journalVersion {
+			return fmt.Errorf("record %d has unsupported version %d", first+i, r.Version)
+		}
+		if _, err := g.Apply(r.PlayerID, r.Command); err != nil {
+			return fmt.Errorf("record %d (%T by player %d) failed: %v", first+i, r.Command, r.PlayerID, err)
+		}
+	}
+	return nil
+}
+
+func (g *Game) adminReplay(c *gin.Context, cu *user.User) (string, game.ActionType, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	err := g.validateAdminAction(cu)
+	if err != nil {
+		return "indonesia/flash_notice", game.None, err
+	}
+
+	err = g.Replay()
+	if err != nil {
+		return "indonesia/flash_notice", game.None, err
+	}
+	return "", game.Save, nil
+}
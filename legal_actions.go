@@ -0,0 +1,218 @@
+package indonesia
+
+import "sort"
+
+// LegalActions returns every command the player having id pid may apply in the
+// current phase and subphase of g.  It returns nil if the player is not a current player.
+func LegalActions(g *Game, pid int) []Command {
+	p := g.PlayerByID(pid)
+	if !g.isCurrentPlayer(p) {
+		return nil
+	}
+
+	for _, p := range g.Players() {
+		p.resetCache()
+	}
+
+	var cmds []Command
+	for _, cmd := range g.candidateActions(p) {
+		if g.allows(pid, cmd) {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// candidateActions returns the commands worth validating for p in the current phase and subphase.
+func (g *Game) candidateActions(p *Player) []Command {
+	cmds := []Command{FinishTurn{}}
+	switch g.Phase {
+	case NewEra:
+		if g.SubPhase == NESelectCard {
+			return append(cmds, PlayCard{Card: 0}, PlayCard{Card: 1})
+		}
+		for _, a := range p.NewCityAreasForCurrentEra() {
+			cmds = append(cmds, PlaceCity{Area: a.ID})
+		}
+	case BidForTurnOrder:
+		for bid := 0; bid <= max(p.Rupiah, 0); bid++ {
+			cmds = append(cmds, TurnOrderBid{Bid: bid})
+		}
+	case Mergers:
+		cmds = append(cmds, g.mergerCandidates(p)...)
+	case Acquisitions:
+		switch g.SubPhase {
+		case AQInitialProduction:
+			for _, a := range g.landAreas() {
+				cmds = append(cmds, PlaceInitialProduct{Area: a.ID})
+			}
+		case AQInitialShip:
+			for _, a := range g.seaAreas() {
+				cmds = append(cmds, PlaceInitialShip{Area: a.ID})
+			}
+		default:
+			cmds = append(cmds, Pass{})
+			for i := range g.AvailableDeeds {
+				cmds = append(cmds, AcquireCompany{Deed: i})
+			}
+		}
+	case Research:
+		if g.SubPhase == RSelectPlayer {
+			for _, op := range g.Players() {
+				cmds = append(cmds, SelectHullPlayer{PlayerID: op.ID()})
+			}
+			break
+		}
+		for t := BidMultiplierTech; t <= HullTech; t++ {
+			cmds = append(cmds, ConductResearch{Technology: t})
+		}
+	case Operations:
+		cmds = append(cmds, g.operationsCandidates(p)...)
+	case CityGrowth:
+		cmds = append(cmds, g.cityGrowthCandidates()...)
+	}
+	return cmds
+}
+
+// turnOrderBids returns the bids worth a bot's consideration for p, in increasing order:
+// nothing, the least bid overtaking each rival, and everything p has.  Rivals
+// yet to bid, or bidding sealed, are taken to bid everything they have.
+func (g *Game) turnOrderBids(p *Player) []int {
+	bids := []int{0}
+	if p.Rupiah <= 0 {
+		return bids
+	}
+
+	m := p.Multiplier()
+	seen := map[int]bool{0: true, p.Rupiah: true}
+	for _, rival := range g.Players() {
+		if rival.Equal(p) {
+			continue
+		}
+
+		total := rival.TotalBid()
+		if rival.Bid == NoBid {
+			total = max(rival.Rupiah, 0) * rival.Multiplier()
+		}
+		if bid := total/m + 1; bid < p.Rupiah && !seen[bid] {
+			seen[bid] = true
+			bids = append(bids, bid)
+		}
+	}
+	sort.Ints(bids)
+	return append(bids, p.Rupiah)
+}
+
+func (g *Game) mergerCandidates(p *Player) []Command {
+	var cmds []Command
+	switch g.SubPhase {
+	case MSelectCompany1:
+		cmds = append(cmds, Pass{})
+		cmap := mergeableCompaniesFor(p)
+		for _, c := range g.Companies() {
+			if len(cmap[c]) > 0 {
+				cmds = append(cmds, AnnounceMerger{OwnerID: c.OwnerID, Slot: c.Slot})
+			}
+		}
+	case MSelectCompany2:
+		if m := g.Merger; m != nil {
+			for _, c := range mergeableCompaniesFor(p)[m.Company1()] {
+				cmds = append(cmds, AnnounceMergerPartner{OwnerID: c.OwnerID, Slot: c.Slot})
+			}
+		}
+	case MBid:
+		if m := g.Merger; m != nil {
+			cmds = append(cmds, MergerBid{Bid: NoBid})
+			for _, bid := range m.BidsFor(p) {
+				cmds = append(cmds, MergerBid{Bid: bid})
+			}
+		}
+	case MSiapFajiCreation:
+		if m := g.SiapFajiMerger; m != nil && m.Company() != nil {
+			for _, a := range m.Company().Areas() {
+				cmds = append(cmds, RemoveRiceSpice{Area: a.ID})
+			}
+			for _, set := range m.RemovalSets() {
+				cmds = append(cmds, RemoveRiceSpiceSet{Areas: set.Areas})
+			}
+		}
+	}
+	return cmds
+}
+
+func (g *Game) operationsCandidates(p *Player) []Command {
+	var cmds []Command
+	switch g.SubPhase {
+	case OPSelectCompany:
+		for _, c := range p.Companies() {
+			cmds = append(cmds, OperateCompany{Slot: c.Slot})
+		}
+	case OPSelectProductionArea:
+		cmds = append(cmds, AcceptProposedFlow{})
+		for i := range g.AlternativePaths {
+			cmds = append(cmds, AcceptAlternativeFlow{Plan: i})
+		}
+		if c := g.SelectedCompany(); c != nil {
+			for _, a := range c.Areas() {
+				cmds = append(cmds, SelectGood{Area: a.ID})
+			}
+		}
+	case OPSelectShip, OPSelectCityOrShip:
+		for _, a := range g.seaAreas() {
+			for i := range a.Shippers {
+				cmds = append(cmds, SelectShip{Area: a.ID, Shipper: i})
+			}
+		}
+		if g.SubPhase == OPSelectCityOrShip {
+			for _, c := range g.Cities() {
+				cmds = append(cmds, SelectCity{Area: c.a.ID})
+			}
+		}
+	case OPFreeExpansion, OPExpansion:
+		cmds = append(cmds, StopExpanding{})
+		if c := g.SelectedCompany(); c != nil && c.IsShippingCompany() {
+			for _, a := range g.freeShippingExpansionAreas() {
+				cmds = append(cmds, ExpandShipping{Area: a.ID})
+			}
+		} else if c != nil {
+			for _, a := range c.ExpansionAreas() {
+				cmds = append(cmds, ExpandProduction{Area: a.ID})
+			}
+		}
+	}
+	return cmds
+}
+
+// cityGrowthCandidates returns a GrowCities command for every combination of
+// size 1 and size 2 cities that uses exactly the available city stones.
+func (g *Game) cityGrowthCandidates() []Command {
+	cmap := g.CityGrowthMap()
+	c1s := citySelections(cmap[Size2], g.C2StonesToUse(cmap))
+	c2s := citySelections(cmap[Size3], g.C3StonesToUse(cmap))
+
+	var cmds []Command
+	for _, ids1 := range c1s {
+		for _, ids2 := range c2s {
+			ids := append(append(AreaIDS{}, ids1...), ids2...)
+			cmds = append(cmds, GrowCities{Areas: ids})
+		}
+	}
+	return cmds
+}
+
+// citySelections returns the areas of every selection of m of cs.
+func citySelections(cs Cities, m int) []AreaIDS {
+	if m <= 0 || m > len(cs) {
+		return []AreaIDS{nil}
+	}
+
+	var selections []AreaIDS
+	for comb := NewComb(len(cs), m); len(comb.Current) > 0; comb = comb.Next() {
+		ids := make(AreaIDS, m)
+		for i, j := range comb.Current {
+			ids[i] = cs[j].a.ID
+		}
+		selections = append(selections, ids)
+	}
+	return selections
+}
@@ -0,0 +1,68 @@
+package indonesia
+
+import (
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// playUntil applies the choices of the HeuristicBot to g until done reports true.
+func playUntil(t *testing.T, g *Game, done func(*Game) bool) {
+	t.Helper()
+	for i := 0; !done(g); i++ {
+		if i == 2000 || g.gameOver() {
+			t.Fatal("game never reached the wanted position")
+		}
+		pid := g.CPUserIndices[0]
+		cmd := HeuristicBot{}.Choose(g, pid)
+		if cmd == nil {
+			t.Fatalf("player %d has no legal action in phase %d", pid, g.Phase)
+		}
+		if _, err := g.Apply(pid, cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestTurnOrderBids checks that every bid a player can afford is legal, and
+// that bots only weigh the bids listed by turnOrderBids.
+func TestTurnOrderBids(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(1, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	playUntil(t, g, func(g *Game) bool { return g.Phase == BidForTurnOrder })
+
+	pid := g.CPUserIndices[0]
+	p := g.PlayerByID(pid)
+	bids := make(map[int]bool)
+	for _, cmd := range LegalActions(g, pid) {
+		if bid, ok := cmd.(TurnOrderBid); ok {
+			bids[bid.Bid] = true
+		}
+	}
+	for bid := 0; bid <= p.Rupiah; bid++ {
+		if !bids[bid] {
+			t.Errorf("bid of %d with %d rupiah is not legal", bid, p.Rupiah)
+		}
+	}
+
+	worth := make(map[int]bool)
+	for _, bid := range g.turnOrderBids(p) {
+		worth[bid] = true
+	}
+	var weighed int
+	for _, cmd := range botActions(g, pid) {
+		if bid, ok := cmd.(TurnOrderBid); ok {
+			weighed++
+			if !worth[bid.Bid] {
+				t.Errorf("bots weigh the bid of %d, want only %v", bid.Bid, g.turnOrderBids(p))
+			}
+		}
+	}
+	if weighed != len(worth) {
+		t.Errorf("bots weigh %d bids, want %d", weighed, len(worth))
+	}
+}
@@ -0,0 +1,378 @@
+package indonesia
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+func init() {
+	gob.Register(time.Time{})
+	gob.Register(new(datastore.Key))
+	gob.Register(new(datastore.Entity))
+	gob.Register(datastore.GeoPoint{})
+	gob.Register([]interface{}{})
+}
+
+// localStoreAttempts bounds the attempts to commit a transaction racing other transactions.
+const localStoreAttempts = 3
+
+// localStoreExt is the extension of the files of a file store.
+const localStoreExt = ".entity"
+
+// localStore keeps entities in memory and, given a directory, in a file per entity.
+// Entities are held as their datastore properties, so they load and save as they
+// would in Cloud Datastore.  Transactions are optimistic: a commit fails with
+// datastore.ErrConcurrentTransaction if an entity read by the transaction has
+// changed, and is then retried.
+//
+// A file store serves a single process.  Stores opened on the same directory
+// by several processes do not see the writes of each other.
+type localStore struct {
+	mu       sync.Mutex
+	dir      string
+	entities map[string]*localEntity
+	lastID   int64
+}
+
+// localEntity is an entity of a localStore.  Among the puts of a transaction,
+// an entity without data deletes the entity stored under its key.
+type localEntity struct {
+	key     *datastore.Key
+	data    []byte
+	version int
+}
+
+// NewMemoryStore returns a GameStore holding entities in memory only, for tests.
+func NewMemoryStore() GameStore {
+	return &localStore{entities: make(map[string]*localEntity)}
+}
+
+// OpenFileStore returns a GameStore holding entities in files of the directory dir,
+// which is created if missing.  The tools under cmd open one when given a
+// directory in place of Cloud Datastore.
+func OpenFileStore(dir string) (GameStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &localStore{dir: dir, entities: make(map[string]*localEntity)}
+	for _, fi := range fis {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != localStoreExt {
+			continue
+		}
+
+		name := strings.TrimSuffix(fi.Name(), localStoreExt)
+		k, err := datastore.DecodeKey(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fi.Name(), err)
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.entities[name] = &localEntity{key: k, data: data, version: 1}
+		s.noteIDs(k)
+	}
+	return s, nil
+}
+
+// noteIDs keeps allocated ids above the ids of k and its ancestors.
+func (s *localStore) noteIDs(k *datastore.Key) {
+	for ; k != nil; k = k.Parent {
+		if k.ID > s.lastID {
+			s.lastID = k.ID
+		}
+	}
+}
+
+// entity returns the entity stored under name and its version, zero if there is none.
+func (s *localStore) entity(name string) (*localEntity, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entities[name]
+	if !ok {
+		return nil, 0
+	}
+	return e, e.version
+}
+
+func (s *localStore) Get(c context.Context, k *datastore.Key, dst interface{}) error {
+	e, _ := s.entity(k.Encode())
+	if e == nil {
+		return datastore.ErrNoSuchEntity
+	}
+	return loadEntity(k, e.data, dst)
+}
+
+func (s *localStore) RunInTransaction(c context.Context, f func(StoreTx) error) error {
+	for attempt := 1; ; attempt++ {
+		tx := &localTx{s: s, read: make(map[string]int), puts: make(map[string]*localEntity)}
+		if err := f(tx); err != nil {
+			return err
+		}
+
+		err := s.commit(tx)
+		if err != datastore.ErrConcurrentTransaction || attempt == localStoreAttempts {
+			return err
+		}
+	}
+}
+
+func (s *localStore) AllocateID(c context.Context, k *datastore.Key) (*datastore.Key, error) {
+	if !k.Incomplete() {
+		return nil, fmt.Errorf("can not allocate an id for complete key %v", k)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	allocated := *k
+	allocated.ID = s.lastID
+	return &allocated, nil
+}
+
+func (s *localStore) Keys(c context.Context, kind string, ancestor *datastore.Key) ([]*datastore.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ks []*datastore.Key
+	for _, e := range s.entities {
+		if e.key.Kind == kind && descends(e.key, ancestor) {
+			ks = append(ks, e.key)
+		}
+	}
+	sort.Slice(ks, func(i, j int) bool { return ks[i].ID < ks[j].ID || (ks[i].ID == ks[j].ID && ks[i].Name < ks[j].Name) })
+	return ks, nil
+}
+
+// descends reports whether k is ancestor or one of its descendants.
+func descends(k, ancestor *datastore.Key) bool {
+	for ; k != nil; k = k.Parent {
+		if k.Equal(ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// commit stores the puts of tx, unless an entity read by tx has changed.  The
+// files of a file store are written aside first and then renamed into place.
+func (s *localStore) commit(tx *localTx) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, version := range tx.read {
+		if e, ok := s.entities[name]; (ok && e.version != version) || (!ok && version != 0) {
+			return datastore.ErrConcurrentTransaction
+		}
+	}
+
+	if s.dir != "" {
+		staged := make(map[string]string, len(tx.puts))
+		for name, e := range tx.puts {
+			if e.data == nil {
+				continue
+			}
+
+			f, err := ioutil.TempFile(s.dir, "commit-")
+			if err == nil {
+				staged[name] = f.Name()
+				_, err = f.Write(e.data)
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+			}
+			if err != nil {
+				for _, tmp := range staged {
+					os.Remove(tmp)
+				}
+				return err
+			}
+		}
+
+		for name, tmp := range staged {
+			if err := os.Rename(tmp, filepath.Join(s.dir, name+localStoreExt)); err != nil {
+				return err
+			}
+		}
+
+		for name, e := range tx.puts {
+			if e.data != nil {
+				continue
+			}
+			if err := os.Remove(filepath.Join(s.dir, name+localStoreExt)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	for name, e := range tx.puts {
+		if e.data == nil {
+			delete(s.entities, name)
+			continue
+		}
+
+		if old, ok := s.entities[name]; ok {
+			e.version = old.version + 1
+		} else {
+			e.version = 1
+		}
+		s.entities[name] = e
+		s.noteIDs(e.key)
+	}
+	return nil
+}
+
+// localTx is a transaction of a localStore.  It records the version of each
+// entity read, and holds its puts and deletes until committed.
+type localTx struct {
+	s    *localStore
+	read map[string]int
+	puts map[string]*localEntity
+}
+
+func (tx *localTx) Get(k *datastore.Key, dst interface{}) error {
+	name := k.Encode()
+	e, version := tx.s.entity(name)
+	tx.read[name] = version
+	if e == nil {
+		return datastore.ErrNoSuchEntity
+	}
+	return loadEntity(k, e.data, dst)
+}
+
+func (tx *localTx) GetMulti(ks []*datastore.Key, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Slice || v.Len() != len(ks) {
+		return fmt.Errorf("get %d entities into %T", len(ks), dst)
+	}
+
+	for i, k := range ks {
+		if err := tx.Get(k, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *localTx) PutMulti(ks []*datastore.Key, es []interface{}) error {
+	if len(ks) != len(es) {
+		return fmt.Errorf("put %d entities under %d keys", len(es), len(ks))
+	}
+
+	for i, k := range ks {
+		if k == nil || k.Incomplete() {
+			return fmt.Errorf("can not put an entity under incomplete key %v", k)
+		}
+
+		data, err := saveEntity(es[i])
+		if err != nil {
+			return err
+		}
+		tx.puts[k.Encode()] = &localEntity{key: k, data: data}
+	}
+	return nil
+}
+
+func (tx *localTx) DeleteMulti(ks []*datastore.Key) error {
+	for _, k := range ks {
+		if k == nil || k.Incomplete() {
+			return fmt.Errorf("can not delete an entity under incomplete key %v", k)
+		}
+		tx.puts[k.Encode()] = &localEntity{key: k}
+	}
+	return nil
+}
+
+// saveEntity encodes the datastore properties of src.
+func saveEntity(src interface{}) ([]byte, error) {
+	var (
+		ps  []datastore.Property
+		err error
+	)
+	if pls, ok := src.(datastore.PropertyLoadSaver); ok {
+		ps, err = pls.Save()
+	} else {
+		ps, err = datastore.SaveStruct(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobProperties(ps)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gobProperties replaces the nil keys and entities among the values of ps,
+// which gob can not encode, with nil values, which load the same.
+func gobProperties(ps []datastore.Property) []datastore.Property {
+	for i := range ps {
+		ps[i].Value = gobValue(ps[i].Value)
+	}
+	return ps
+}
+
+func gobValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *datastore.Key:
+		if v == nil {
+			return nil
+		}
+	case *datastore.Entity:
+		if v == nil {
+			return nil
+		}
+		gobProperties(v.Properties)
+	case []interface{}:
+		for i := range v {
+			v[i] = gobValue(v[i])
+		}
+	}
+	return v
+}
+
+// loadEntity loads the datastore properties encoded in data into dst, stored under k.
+func loadEntity(k *datastore.Key, data []byte, dst interface{}) error {
+	var ps []datastore.Property
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&ps); err != nil {
+		return err
+	}
+
+	var err error
+	if pls, ok := dst.(datastore.PropertyLoadSaver); ok {
+		err = pls.Load(ps)
+	} else {
+		err = datastore.LoadStruct(dst, ps)
+	}
+	if err != nil {
+		return err
+	}
+
+	if kl, ok := dst.(datastore.KeyLoader); ok {
+		return kl.LoadKey(k)
+	}
+	return nil
+}
@@ -2,7 +2,11 @@ package indonesia
 
 import (
 	"fmt"
+	"html"
 	"html/template"
+	"io"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/SlothNinja/game"
@@ -56,3 +60,21 @@ func (gl GameLog) Last() (e Entryer) {
 	}
 	return
 }
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// WriteLog writes the game log to w as plain text, one entry per line.
+func (g *Game) WriteLog(w io.Writer) error {
+	c := withGame(new(gin.Context), g)
+	for _, e := range g.Log {
+		text := htmlTag.ReplaceAllString(string(e.HTML(c)), " ")
+		text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+		if text == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s | %s\n", e.PhaseName(), text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package indonesia
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 type FlowID struct {
 	AreaID   AreaID
@@ -57,144 +61,173 @@ func (sf subflow) String() (s string) {
 // Sufficiently large number that it does not limit max flow
 const infinity = 10000
 
-func (c *Company) maxFlow() (int, flowMatrix) {
-	flow1, fm1 := c.maxFlow2()
-	//	c.g.debugf("initial flow: %d delivered: %s", flow1, fm1)
-	if cp := c.g.CurrentPlayer(); cp.hasShippingCompany() {
-		hullsizes := c.g.getHullSizes()
-		for _, p := range c.g.Players() {
-			if pid := p.ID(); pid == cp.ID() {
-				p.Technologies[HullTech] = hullsizes[pid]
-			} else {
-				p.Technologies[HullTech] = -1
-			}
-		}
-		for i := 0; i < 5; i++ {
-			for _, p := range c.g.Players() {
-				if pid := p.ID(); pid == cp.ID() {
-					p.Technologies[HullTech] = hullsizes[pid]
-				} else {
-					p.Technologies[HullTech] = min(p.Technologies[HullTech]+1, hullsizes[pid])
-				}
-				//				c.g.debugf("pid: %d hullsize: %d", p.ID(), p.Technologies[HullTech])
-			}
-			flow2, fm2 := c.maxFlow2()
-			//			c.g.debugf("flow1: %d fm1: %s", flow1, fm1)
-			//			c.g.debugf("ProposedShips: %#v", c.g.ProposedShips(fm1))
-			//			c.g.debugf("flow2: %d fm2: %s", flow2, fm2)
-			//			c.g.debugf("ProposedShips: %#v", c.g.ProposedShips(fm2))
-			if flow2 == flow1 {
-				c.g.restoreHullSizes(hullsizes)
-				return flow2, fm2
-			}
-		}
-		c.g.restoreHullSizes(hullsizes)
-	}
-	return flow1, fm1
+// flowNetwork is the delivery network of a company.  The capacity of each
+// ship is fixed from its shipping company's hull size when the network is
+// built, so solving the network neither consults nor alters the players'
+// technologies.
+//
+// cityCosts and shipCosts add to the cost of each good delivered to a city
+// or boarding a ship of a player, steering the solver among the maximum
+// deliveries without changing how many goods are delivered.
+//
+// carried, boarded and fed describe a delivery step being validated:
+// the node reached by a good the operator has started delivering, the
+// input node of the ship the good would board, and the city the good
+// would be delivered to.  Each is unset (noFID or NoArea) otherwise.
+type flowNetwork struct {
+	*Company
+	hulls     map[Province]int
+	cityCosts map[AreaID]int
+	shipCosts map[int]int
+	carried   FlowID
+	boarded   FlowID
+	fed       AreaID
 }
 
-func (g *Game) getHullSizes() map[int]int {
-	hs := make(map[int]int, 0)
-	for _, p := range g.Players() {
-		hs[p.ID()] = p.Technologies[HullTech]
+func (c *Company) network() *flowNetwork {
+	hulls := make(map[Province]int, 0)
+	for province, shipper := range c.g.ShippingCompanies() {
+		hulls[province] = shipper.HullSize()
 	}
-	return hs
+	return &flowNetwork{Company: c, hulls: hulls, carried: noFID, boarded: noFID, fed: NoArea}
 }
 
-func (g *Game) restoreHullSizes(hs map[int]int) {
-	for pid, size := range hs {
-		g.PlayerByID(pid).Technologies[HullTech] = size
-	}
+// maxFlow returns the largest delivery available to the company and,
+// among deliveries of that size, the one earning its owner the most income.
+func (c *Company) maxFlow() (int, flowMatrix) {
+	return c.network().minCostFlow()
 }
 
-// Edmonds Karp
-func (c *Company) maxFlow2() (flow int, fm flowMatrix) {
-	fm = make(flowMatrix, 0)
-	for newFlow, parentTable := c.search(fm); newFlow > 0; newFlow, parentTable = c.search(fm) {
-		flow += newFlow
-
-		// Backtrach search and write flow
-		v := targetFID
-		for v != sourceFID {
-			u := parentTable[v]
-			if _, ok := fm[u]; !ok {
-				fm[u] = make(subflow, 0)
-			}
-			fm[u][v] += newFlow
+// MaxDeliveries returns the most goods the company can deliver with the ships now on the board.
+func (c *Company) MaxDeliveries() int {
+	flow, _ := c.maxFlow()
+	return flow
+}
 
-			if _, ok := fm[v]; !ok {
-				fm[v] = make(subflow, 0)
-			}
-			fm[v][u] -= newFlow
-			v = u
-		}
-		//		c.g.debugf("flow: %d fm: %s", flow, fm)
-	}
-	return flow, fm
+// shipFee is the rupiah paid to a ship's owner for each good the ship carries
+// for another player's company.
+const shipFee = 5
+
+// minCostFlow returns a maximum flow of n and, among those, the cheapest.
+func (n *flowNetwork) minCostFlow() (int, flowMatrix) {
+	fg := n.compile()
+	flow := fg.minCostFlow()
+	return flow, fg.flows()
 }
 
-type parentTable map[FlowID]FlowID
-type foundCapTo map[FlowID]int
-
-func (c *Company) search(fm flowMatrix) (int, parentTable) {
-	parentTable := make(parentTable, 0)
-	foundCapTo := make(foundCapTo, 0)
-
-	// make sure source is not rediscovered
-	parentTable[sourceFID] = noFID
-	foundCapTo[sourceFID] = infinity
-
-	q := make(queue, 0)
-	q.push(sourceFID)
-	for len(q) > 0 {
-		u := q.pop()
-		ids := c.neighboringFIDSFor(u)
-		//		c.g.debugf("\n\n=============\nneighboringFIDSFor(%d): %s", u, ids)
-		for _, v := range ids {
-
-			// If there is residual capacity and v is not seen before in search
-			capBetween := c.capBetween(u, v)
-			//			c.g.debugf("\n\ncapBetween(%d, %d): %d", u, v, capBetween)
-			residualCap := capBetween - fm[u][v]
-			//			c.g.debugf("residualCapBetween(%d, %d): %d", u, v, residualCap)
-			if _, seen := parentTable[v]; residualCap > 0 && !seen {
-				parentTable[v] = u
-				//                                c.g.debugf("parentTable[%s]: %#v", v, parentTable[v])
-				foundCapTo[v] = min(foundCapTo[u], residualCap)
-				if v != targetFID {
-					q.push(v)
-				} else {
-					return foundCapTo[targetFID], parentTable
-				}
-			}
-		}
+// costBetween returns the cost to the company's owner of moving a good from one node to the other.
+// Only boarding a ship owned by another player costs anything; moving a good back off such a ship
+// refunds the fee.
+func (n *flowNetwork) costBetween(from, to FlowID) int {
+	switch {
+	case from == sourceFID && to == n.carried:
+		return -steer
+	case from == n.carried && to == sourceFID:
+		return steer
+	case to == targetFID:
+		return n.cityCosts[from.AreaID]
+	case from == targetFID:
+		return -n.cityCosts[to.AreaID]
+	case from.AreaID != to.AreaID || !seaSet.has(from.AreaID):
+		return 0
+	}
 
+	fee := n.shipCosts[from.PID]
+	if from.PID != n.OwnerID {
+		fee += shipFee
+	}
+	switch {
+	case from.IO == shipInput && to.IO == shipOutput:
+		return fee
+	case from.IO == shipOutput && to.IO == shipInput:
+		return -fee
+	default:
+		return 0
 	}
-	return 0, parentTable
 }
 
-type queue FlowIDS
+// canDeliver reports whether the company can still deliver required goods, counting any good
+// carried to node carried, once a good boards the ship at input node boarded or is delivered
+// to the city in area fed.
+func (c *Company) canDeliver(required int, carried, boarded FlowID, fed AreaID) bool {
+	n := c.network()
+	n.carried, n.boarded, n.fed = carried, boarded, fed
+	flow, fm := n.minCostFlow()
+	return flow >= required && (carried == noFID || fm[sourceFID][carried] > 0)
+}
 
-func (q queue) popS() (FlowID, queue) {
-	if len(q) > 0 {
-		return q[0], q[1:]
+// maxAlternatives caps the number of alternative delivery plans.
+const maxAlternatives = 6
+
+// steer is the cost that steers deliveries towards or away from a city or a player's ships.
+// It exceeds the fees payable for any one good, so steering takes priority over income.
+const steer = 1000
+
+// alternativeFlows returns maximum flows of the company differing from fm, and from each other,
+// in the goods delivered to each city or in the ships used of each other player.
+// Each is found by steering the solver towards a city fm leaves unfed, away from a city fm feeds,
+// or away from the ships of a player fm pays.
+func (c *Company) alternativeFlows(fm flowMatrix) []flowMatrix {
+	seen := map[string]bool{fm.signature(c.OwnerID): true}
+	var fms []flowMatrix
+
+	fed := fm.cities()
+	var steers []map[AreaID]int
+	for _, city := range c.g.Cities() {
+		aid := city.a.ID
+		switch {
+		case fed[aid] > 0:
+			steers = append(steers, map[AreaID]int{aid: steer})
+		case city.hasDemandFor(c.Goods()):
+			steers = append(steers, map[AreaID]int{aid: -steer})
+		}
+	}
+
+	for _, cityCosts := range steers {
+		n := c.network()
+		n.cityCosts = cityCosts
+		if fms = appendFlow(fms, seen, n, c.OwnerID); len(fms) == maxAlternatives {
+			return fms
+		}
 	}
-	return noFID, q
-}
 
-func (q *queue) pop() FlowID {
-	id, nq := q.popS()
-	*q = nq
-	return id
+	for _, p := range c.g.Players() {
+		if p.ID() == c.OwnerID || c.g.ProposedShips(fm)[p.ID()] == 0 {
+			continue
+		}
+		n := c.network()
+		n.shipCosts = map[int]int{p.ID(): steer}
+		if fms = appendFlow(fms, seen, n, c.OwnerID); len(fms) == maxAlternatives {
+			return fms
+		}
+	}
+	return fms
 }
 
-func (q queue) pushS(id FlowID) queue {
-	return append(q, id)
+// appendFlow appends the flow of n to fms, unless its signature was seen.
+func appendFlow(fms []flowMatrix, seen map[string]bool, n *flowNetwork, pid int) []flowMatrix {
+	_, fm := n.minCostFlow()
+	sig := fm.signature(pid)
+	if seen[sig] {
+		return fms
+	}
+	seen[sig] = true
+	return append(fms, fm)
 }
 
-func (q *queue) push(id FlowID) {
-	nq := q.pushS(id)
-	*q = nq
+// signature identifies the goods fm delivers to each city and the ships it uses of players other than pid.
+func (fm flowMatrix) signature(pid int) string {
+	var parts []string
+	for aid, v := range fm.cities() {
+		parts = append(parts, fmt.Sprintf("c%d:%d", aid, v))
+	}
+	for id, v := range fm.ships() {
+		if id != pid {
+			parts = append(parts, fmt.Sprintf("s%d:%d", id, v))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
 }
 
 //const (
@@ -260,58 +293,86 @@ func toFlowID(aid AreaID, args ...int) FlowID {
 //	return nil, NoPlayerID, -1, -1
 //}
 
-func (c *Company) capBetween(from, to FlowID) int {
-	fromArea := c.g.GetArea(from.AreaID)
-	toArea := c.g.GetArea(to.AreaID)
-	shippers := c.g.ShippingCompanies()
+func (n *flowNetwork) capBetween(from, to FlowID) int {
+	fromArea := n.g.GetArea(from.AreaID)
+	toArea := n.g.GetArea(to.AreaID)
 	switch {
+	case from == sourceFID && to == n.carried && toArea.IsSea():
+		return 1
 	case from == sourceFID && toArea.IsLand() && toArea.hasProducer():
-		zone := c.ZoneFor(toArea)
-		return len(zone.AreaIDS)
+		return n.production(n.ZoneFor(toArea))
 	case fromArea == nil:
 		return 0
 	case fromArea.IsLand() && fromArea.hasCity() && to == targetFID:
-		demand := fromArea.City.demandFor(c.Goods())
-		return demand
+		return n.demand(fromArea)
 	case toArea == nil:
 		return 0
 	case fromArea.IsLand() && fromArea.hasProducer() &&
 		toArea.IsSea() && toArea.hasAShipper():
-		production := len(c.ZoneFor(fromArea).AreaIDS)
-		shipper := shippers[to.Province]
-		return min(shipper.HullSize(), production)
+		production := n.production(n.ZoneFor(fromArea))
+		return min(n.hulls[to.Province], production)
 	case fromArea.IsSea() && fromArea.hasAShipper() &&
 		toArea.IsLand() && toArea.hasCity():
-		demand := toArea.City.demandFor(c.Goods())
-		shipper := shippers[from.Province]
-		capacity := min(shipper.HullSize(), demand)
-		return capacity
+		return min(n.hulls[from.Province], n.demand(toArea))
 	case fromArea.IsSea() && fromArea == toArea:
 		if from.IO == shipInput && to.IO == shipOutput {
-			shipper := shippers[from.Province]
-			return shipper.HullSize()
+			return n.room(fromArea, from)
 		}
 		return 0
 	case fromArea.IsSea() && fromArea != toArea && (from.IO == shipInput || to.IO == shipOutput):
 		return 0
 	case fromArea.IsSea() && fromArea.hasAShipper() &&
 		toArea.IsSea() && toArea.hasAShipper() && to.Province == from.Province:
-		shipper := shippers[from.Province]
-		return shipper.HullSize()
+		return n.hulls[from.Province]
 	default:
 		return 0
 	}
 }
 
-func (c *Company) neighboringFIDSFor(from FlowID) (fids FlowIDS) {
-	area := c.g.GetArea(from.AreaID)
+// production returns the goods of zone not yet delivered.
+func (n *flowNetwork) production(zone *Zone) int {
+	production := 0
+	for _, a := range zone.Areas() {
+		if !a.Used {
+			production++
+		}
+	}
+	return production
+}
+
+// demand returns the goods of the company the city in area a still takes.
+func (n *flowNetwork) demand(a *Area) int {
+	demand := a.City.demandFor(n.Goods())
+	if a.ID == n.fed {
+		demand--
+	}
+	return demand
+}
+
+// room returns the goods the ship at input node fid of sea area a may still carry.
+func (n *flowNetwork) room(a *Area, fid FlowID) int {
+	room := n.hulls[fid.Province]
+	if fid.Index >= 0 && fid.Index < len(a.Shippers) {
+		room -= a.Shippers[fid.Index].Delivered
+	}
+	if fid == n.boarded {
+		room--
+	}
+	return room
+}
+
+func (n *flowNetwork) neighboringFIDSFor(from FlowID) (fids FlowIDS) {
+	area := n.g.GetArea(from.AreaID)
 	switch {
 	case from == sourceFID:
-		for _, zone := range c.Zones {
+		for _, zone := range n.Zones {
 			fids = append(fids, toFlowID(zone.AreaIDS[0]))
 		}
+		if seaSet.has(n.carried.AreaID) {
+			fids = append(fids, n.carried)
+		}
 	case from == targetFID:
-		for _, city := range c.g.Cities() {
+		for _, city := range n.g.Cities() {
 			fids = append(fids, toFlowID(city.a.ID))
 		}
 	case area.hasCity():
@@ -323,7 +384,7 @@ func (c *Company) neighboringFIDSFor(from FlowID) (fids FlowIDS) {
 		}
 		fids = append(fids, targetFID)
 	case area.hasProducer():
-		for _, a := range c.ZoneFor(area).adjacentAreas(hasAShipper) {
+		for _, a := range n.ZoneFor(area).adjacentAreas(hasAShipper) {
 			for i, shipper := range a.Shippers {
 				fid := toFlowID(a.ID, shipper.OwnerID, i, shipInput, shipper.Province().Int())
 				fids = append(fids, fid)
@@ -331,12 +392,15 @@ func (c *Company) neighboringFIDSFor(from FlowID) (fids FlowIDS) {
 		}
 		fids = append(fids, sourceFID)
 	case area.IsSea():
+		if from == n.carried {
+			fids = append(fids, sourceFID)
+		}
 		for _, a := range area.adjacentAreas() {
 			switch {
 			case a.hasCity() && from.IO == shipOutput:
 				fids = append(fids, toFlowID(a.ID))
-			case a.hasProducer() && from.IO == shipInput && c.ZoneFor(a) != nil:
-				fids = append(fids, toFlowID(c.ZoneFor(a).AreaIDS[0]))
+			case a.hasProducer() && from.IO == shipInput && n.ZoneFor(a) != nil:
+				fids = append(fids, toFlowID(n.ZoneFor(a).AreaIDS[0]))
 			case a.IsSea() && from.IO == shipOutput:
 				for i, shipper := range a.Shippers {
 					if from.Province == shipper.Province() {
@@ -370,9 +434,14 @@ func (c *Company) neighboringFIDSFor(from FlowID) (fids FlowIDS) {
 }
 
 func (g *Game) ProposedShips(fm flowMatrix) ShipperIncomeMap {
+	return fm.ships()
+}
+
+// ships returns the number of goods carried by the ships of each player.
+func (fm flowMatrix) ships() ShipperIncomeMap {
 	ships := make(ShipperIncomeMap, 0)
 	for from, sf := range fm {
-		if g.isSeaID(from.AreaID) && from.IO == shipInput {
+		if seaSet.has(from.AreaID) && from.IO == shipInput {
 			for to, v := range sf {
 				if v > 0 && to.IO == shipOutput {
 					ships[from.PID] += v
@@ -394,8 +463,13 @@ func (g *Game) usedOtherShips(p *Player, fm flowMatrix) int {
 }
 
 func (g *Game) ProposedCities() map[AreaID]int {
+	return g.ProposedPath.cities()
+}
+
+// cities returns the number of goods delivered to each city.
+func (fm flowMatrix) cities() map[AreaID]int {
 	cities := make(map[AreaID]int, 0)
-	for from, v := range g.ProposedPath[targetFID] {
+	for from, v := range fm[targetFID] {
 		cities[from.AreaID] = v * -1
 	}
 	return cities
@@ -0,0 +1,214 @@
+package indonesia
+
+import (
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// The map-based solvers and adjacency walks below are those the flowGraph and
+// areaSet code replaced.  They are kept to benchmark the new code against and
+// to check that both give the same results.
+
+type parentTable map[FlowID]FlowID
+type foundCapTo map[FlowID]int
+
+// Edmonds Karp
+func (n *flowNetwork) maxFlow2() (flow int, fm flowMatrix) {
+	fm = make(flowMatrix, 0)
+	for newFlow, parentTable := n.search(fm); newFlow > 0; newFlow, parentTable = n.search(fm) {
+		flow += newFlow
+		fm.augment(parentTable, newFlow)
+	}
+	return flow, fm
+}
+
+// augment writes newFlow along the path to the target recorded in parentTable.
+func (fm flowMatrix) augment(parentTable parentTable, newFlow int) {
+	v := targetFID
+	for v != sourceFID {
+		u := parentTable[v]
+		if _, ok := fm[u]; !ok {
+			fm[u] = make(subflow, 0)
+		}
+		fm[u][v] += newFlow
+
+		if _, ok := fm[v]; !ok {
+			fm[v] = make(subflow, 0)
+		}
+		fm[v][u] -= newFlow
+		v = u
+	}
+}
+
+func (n *flowNetwork) search(fm flowMatrix) (int, parentTable) {
+	parentTable := make(parentTable, 0)
+	foundCapTo := make(foundCapTo, 0)
+
+	// make sure source is not rediscovered
+	parentTable[sourceFID] = noFID
+	foundCapTo[sourceFID] = infinity
+
+	q := make(queue, 0)
+	q.push(sourceFID)
+	for len(q) > 0 {
+		u := q.pop()
+		for _, v := range n.neighboringFIDSFor(u) {
+			// If there is residual capacity and v is not seen before in search
+			residualCap := n.capBetween(u, v) - fm[u][v]
+			if _, seen := parentTable[v]; residualCap > 0 && !seen {
+				parentTable[v] = u
+				foundCapTo[v] = min(foundCapTo[u], residualCap)
+				if v != targetFID {
+					q.push(v)
+				} else {
+					return foundCapTo[targetFID], parentTable
+				}
+			}
+		}
+	}
+	return 0, parentTable
+}
+
+// mapMinCostFlow is minCostFlow by successive shortest paths over maps.
+func (n *flowNetwork) mapMinCostFlow() (flow int, fm flowMatrix) {
+	fm = make(flowMatrix, 0)
+	for newFlow, parentTable := n.cheapestPath(fm); newFlow > 0; newFlow, parentTable = n.cheapestPath(fm) {
+		flow += newFlow
+		fm.augment(parentTable, newFlow)
+	}
+	return flow, fm
+}
+
+// cheapestPath finds the cheapest path from source to target having residual capacity.
+// Residual costs can be negative, so the search is Bellman-Ford with a work queue.
+func (n *flowNetwork) cheapestPath(fm flowMatrix) (int, parentTable) {
+	parentTable := make(parentTable, 0)
+	costTo := map[FlowID]int{sourceFID: 0}
+	queued := map[FlowID]bool{sourceFID: true}
+
+	parentTable[sourceFID] = noFID
+
+	q := make(queue, 0)
+	q.push(sourceFID)
+	for len(q) > 0 {
+		u := q.pop()
+		queued[u] = false
+		for _, v := range n.neighboringFIDSFor(u) {
+			if n.capBetween(u, v)-fm[u][v] <= 0 {
+				continue
+			}
+			cost := costTo[u] + n.costBetween(u, v)
+			if found, seen := costTo[v]; seen && found <= cost {
+				continue
+			}
+			costTo[v], parentTable[v] = cost, u
+			if !queued[v] {
+				queued[v] = true
+				q.push(v)
+			}
+		}
+	}
+
+	if _, found := costTo[targetFID]; !found {
+		return 0, parentTable
+	}
+
+	newFlow := infinity
+	for v := targetFID; v != sourceFID; v = parentTable[v] {
+		u := parentTable[v]
+		newFlow = min(newFlow, n.capBetween(u, v)-fm[u][v])
+	}
+	return newFlow, parentTable
+}
+
+type queue FlowIDS
+
+func (q *queue) pop() FlowID {
+	id := (*q)[0]
+	*q = (*q)[1:]
+	return id
+}
+
+func (q *queue) push(id FlowID) {
+	*q = append(*q, id)
+}
+
+// mapExpansionAreas is ExpansionAreas walking the adjacent areas of adjacentAreasMap.
+func (c *Company) mapExpansionAreas() Areas {
+	var expansionAreas Areas
+	as := c.Zones.Areas()
+	for _, area := range as {
+		if !c.IsProductionCompany() {
+			for _, a := range area.AdjacentSeaAreas() {
+				if !expansionAreas.include(a) {
+					expansionAreas = append(expansionAreas, a)
+				}
+			}
+			continue
+		}
+		for _, a := range area.AdjacentLandAreas() {
+			if !a.hasProducer() && !a.hasCity() && !a.adjacentAreaHasCompetingCompanyFor(c) &&
+				!expansionAreas.include(a) && !as.include(a) {
+				expansionAreas = append(expansionAreas, a)
+			}
+		}
+	}
+	return expansionAreas
+}
+
+// TestMapFlows checks that the flowGraph and areaSet code give the results of the map-based code.
+func TestMapFlows(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(1, "a", "b", "c", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step := 0; step < 600 && !g.gameOver(); step++ {
+		if step%10 == 0 {
+			for _, c := range g.Companies() {
+				want, _ := c.network().mapMinCostFlow()
+				maxFlow, _ := c.network().maxFlow2()
+				if got := c.MaxDeliveries(); got != want || maxFlow != want {
+					t.Errorf("step %d: %v delivers %d, want %d by min cost flow and %d by max flow",
+						step, c, got, want, maxFlow)
+				}
+
+				expansions, wantExpansions := c.ExpansionAreas(), c.mapExpansionAreas()
+				if len(expansions) != len(wantExpansions) || len(expansions.exclude(wantExpansions)) != 0 {
+					t.Errorf("step %d: %v expands to %v, want %v", step, c, expansions.ids(), wantExpansions.ids())
+				}
+			}
+		}
+
+		pid := g.CPUserIndices[0]
+		if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMaxDeliveriesMaps(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		for _, c := range g.Companies() {
+			c.network().mapMinCostFlow()
+		}
+	})
+}
+
+func BenchmarkMaxFlow2(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		for _, c := range g.Companies() {
+			c.network().maxFlow2()
+		}
+	})
+}
+
+func BenchmarkExpansionAreasMaps(b *testing.B) {
+	benchmarkPositionsWith(b, func(g *Game) {
+		for _, c := range g.Companies() {
+			c.mapExpansionAreas()
+		}
+	})
+}
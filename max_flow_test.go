@@ -0,0 +1,175 @@
+package indonesia
+
+import (
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// manualDelivery makes the deliveries of the operating company one step at a time,
+// taking the legal steps in turn, and checks the flow network after each step.
+type manualDelivery struct {
+	t        *testing.T
+	steps    int
+	hops     int
+	operated int
+}
+
+func (m *manualDelivery) deliver(g *Game, pid int) {
+	m.t.Helper()
+
+	var steps []Command
+	for _, cmd := range LegalActions(g, pid) {
+		switch cmd.(type) {
+		case SelectGood, SelectShip, SelectCity:
+			steps = append(steps, cmd)
+		}
+	}
+	if len(steps) == 0 {
+		m.t.Fatalf("seed %d: no delivery step is legal with %d of %d goods delivered",
+			g.Seed, g.SelectedCompany().Delivered(), g.RequiredDeliveries)
+	}
+
+	cmd := steps[m.steps%len(steps)]
+	m.steps++
+
+	com := g.SelectedCompany()
+	n := com.network()
+	switch cmd := cmd.(type) {
+	case SelectGood:
+		zone := com.ZoneFor(g.GetArea(cmd.Area))
+		before := n.production(zone)
+		m.apply(g, pid, cmd)
+		if after := n.production(zone); after != before-1 {
+			m.t.Errorf("seed %d: production of zone is %d after selecting a good, want %d", g.Seed, after, before-1)
+		}
+	case SelectShip:
+		if g.SubPhase == OPSelectCityOrShip {
+			m.hops++
+		}
+		a := g.GetArea(cmd.Area)
+		s := a.Shippers[cmd.Shipper]
+		fid := toFlowID(a.ID, s.OwnerID, cmd.Shipper, shipInput, s.Province().Int())
+		before, delivered := n.room(a, fid), s.Delivered
+		m.apply(g, pid, cmd)
+		if s.Delivered != delivered+1 {
+			m.t.Errorf("seed %d: ship carried %d goods after boarding, want %d", g.Seed, s.Delivered, delivered+1)
+		}
+		if after := n.room(a, fid); after != before-1 {
+			m.t.Errorf("seed %d: room of ship is %d after boarding, want %d", g.Seed, after, before-1)
+		}
+	case SelectCity:
+		a := g.GetArea(cmd.Area)
+		before := n.demand(a)
+		m.apply(g, pid, cmd)
+		if after := n.demand(a); after != before-1 {
+			m.t.Errorf("seed %d: demand of city is %d after a delivery, want %d", g.Seed, after, before-1)
+		}
+		if g.Phase != Operations || g.SubPhase != OPSelectProductionArea {
+			m.operated++
+			if com.Delivered() != g.RequiredDeliveries {
+				m.t.Errorf("seed %d: company delivered %d goods, want %d", g.Seed, com.Delivered(), g.RequiredDeliveries)
+			}
+			return
+		}
+		left := g.RequiredDeliveries - com.Delivered()
+		if flow, _ := com.maxFlow(); flow < left {
+			m.t.Errorf("seed %d: company can deliver %d more goods after a delivery, want at least %d", g.Seed, flow, left)
+		}
+	}
+}
+
+func (m *manualDelivery) apply(g *Game, pid int, cmd Command) {
+	m.t.Helper()
+	if _, err := g.Apply(pid, cmd); err != nil {
+		m.t.Fatalf("seed %d: %T: %v", g.Seed, cmd, err)
+	}
+}
+
+func delivering(g *Game) bool {
+	if g.Phase != Operations {
+		return false
+	}
+	switch g.SubPhase {
+	case OPSelectProductionArea, OPSelectShip, OPSelectCityOrShip:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestManualDeliveries plays games making every delivery one good at a time, and
+// checks that the steps allowed never leave a company short of its required deliveries.
+func TestManualDeliveries(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	m := &manualDelivery{t: t}
+	for seed := int64(1); seed <= 4; seed++ {
+		g, err := NewHeadless(seed, "a", "b", "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < maxBotActions && len(g.CPUserIndices) > 0 && !g.gameOver(); i++ {
+			pid := g.CPUserIndices[0]
+			if delivering(g) {
+				m.deliver(g, pid)
+				continue
+			}
+			m.apply(g, pid, HeuristicBot{}.Choose(g, pid))
+		}
+	}
+
+	switch {
+	case m.operated == 0:
+		t.Error("no company delivered goods")
+	case m.hops == 0:
+		t.Error("no good was carried by more than one ship")
+	}
+}
+
+// TestCanDeliver checks canDeliver against the deliveries of the proposed plan.
+func TestCanDeliver(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(1, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxBotActions && !(delivering(g) && g.SubPhase == OPSelectProductionArea); i++ {
+		if len(g.CPUserIndices) == 0 || g.gameOver() {
+			t.Fatal("no company delivered goods")
+		}
+		pid := g.CPUserIndices[0]
+		if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	com := g.SelectedCompany()
+	required := g.RequiredDeliveries
+	if !com.canDeliver(required, noFID, noFID, NoArea) {
+		t.Errorf("canDeliver(%d) = false, want true", required)
+	}
+	if com.canDeliver(required+1, noFID, noFID, NoArea) {
+		t.Errorf("canDeliver(%d) = true, want false", required+1)
+	}
+
+	for _, a := range com.Areas() {
+		a.Used = true
+	}
+	if com.canDeliver(1, noFID, noFID, NoArea) {
+		t.Error("canDeliver(1) = true with every good used, want false")
+	}
+	for _, a := range com.Areas() {
+		a.Used = false
+	}
+
+	for _, a := range g.seaAreas() {
+		for _, s := range a.Shippers {
+			s.Delivered = s.HullSize()
+		}
+	}
+	if com.canDeliver(1, noFID, noFID, NoArea) {
+		t.Error("canDeliver(1) = true with every ship full, want false")
+	}
+}
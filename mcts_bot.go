@@ -0,0 +1,294 @@
+package indonesia
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/SlothNinja/game"
+)
+
+// Strength selects the search budget of an MCTSBot.
+// Heuristic selects the HeuristicBot, which does not search at all.
+type Strength int
+
+const (
+	Heuristic Strength = iota
+	Easy
+	Medium
+	Hard
+	Expert
+)
+
+var strengthStrings = map[Strength]string{
+	Heuristic: "Heuristic",
+	Easy:      "Easy",
+	Medium:    "Medium",
+	Hard:      "Hard",
+	Expert:    "Expert",
+}
+
+func (s Strength) String() string {
+	return strengthStrings[s]
+}
+
+// budgets maps each strength to its iteration and time budget.
+var budgets = map[Strength]struct {
+	iterations int
+	duration   time.Duration
+}{
+	Easy:   {iterations: 25, duration: time.Second},
+	Medium: {iterations: 100, duration: 3 * time.Second},
+	Hard:   {iterations: 400, duration: 10 * time.Second},
+	Expert: {iterations: 1600, duration: 30 * time.Second},
+}
+
+const (
+	// mctsBranching caps the actions searched at each decision, keeping those the heuristics rate best.
+	mctsBranching = 8
+	// mctsExploration is the UCT exploration constant.
+	mctsExploration = 1.0
+	// mctsRolloutSteps caps the actions of a single rollout.
+	mctsRolloutSteps = 500
+	// mctsEpsilon is the chance that a rollout takes a random action instead of the heuristic one.
+	mctsEpsilon = 0.1
+)
+
+// MCTSBot is a Bot that chooses actions by Monte Carlo tree search.
+// Each iteration clones the game, applies the actions along a path of the
+// search tree, and then plays the heuristic bot to the end of the round,
+// where the position of every player is evaluated.
+// Search stops once either its iteration or its time budget is spent.
+type MCTSBot struct {
+	Iterations int
+	Duration   time.Duration
+	rand       *rand.Rand
+}
+
+// NewMCTSBot returns a search bot with the budget of strength s.
+func NewMCTSBot(s Strength, seed int64) *MCTSBot {
+	b, ok := budgets[s]
+	if !ok {
+		b = budgets[Medium]
+	}
+	return &MCTSBot{
+		Iterations: b.iterations,
+		Duration:   b.duration,
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+type mctsNode struct {
+	parent   *mctsNode
+	cmd      Command
+	pid      int
+	children []*mctsNode
+	untried  []Command
+	visits   int
+	rewards  map[int]float64
+}
+
+// Choose implements Bot.
+func (b *MCTSBot) Choose(g *Game, pid int) Command {
+	cmds := rankedActions(g, pid)
+	switch len(cmds) {
+	case 0:
+		return nil
+	case 1:
+		return cmds[0]
+	}
+
+	if b.rand == nil {
+		b.rand = rand.New(rand.NewSource(g.Seed))
+	}
+
+	root := &mctsNode{pid: pid, untried: cmds}
+	var deadline time.Time
+	if b.Duration > 0 {
+		deadline = time.Now().Add(b.Duration)
+	}
+	for i := 0; i < b.Iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		if !b.iterate(g, root) {
+			break
+		}
+	}
+
+	if len(root.children) == 0 {
+		return cmds[0]
+	}
+	best := root.children[0]
+	for _, child := range root.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.cmd
+}
+
+// iterate runs one search iteration from root and reports whether it succeeded.
+func (b *MCTSBot) iterate(g *Game, root *mctsNode) bool {
+	sim, err := g.Clone()
+	if err != nil {
+		return false
+	}
+	sim.forgetSealedBids(root.pid)
+
+	// Selection
+	node := root
+	for len(node.untried) == 0 && len(node.children) > 0 {
+		node = node.selectChild()
+		if _, err := sim.Apply(node.parent.pid, node.cmd); err != nil {
+			return false
+		}
+	}
+
+	// Expansion
+	if len(node.untried) > 0 {
+		cmd := node.untried[0]
+		node.untried = node.untried[1:]
+		pid := node.pid
+		if _, err := sim.Apply(pid, cmd); err != nil {
+			return false
+		}
+		child := &mctsNode{parent: node, cmd: cmd, pid: NoPlayerID}
+		if len(sim.CPUserIndices) > 0 && !sim.gameOver() {
+			child.pid = sim.CPUserIndices[0]
+			child.untried = rankedActions(sim, child.pid)
+		}
+		node.children = append(node.children, child)
+		node = child
+	}
+
+	// Simulation
+	rewards := b.rollout(sim, g.Turn)
+
+	// Backpropagation
+	for ; node != nil; node = node.parent {
+		node.visits++
+		if node.rewards == nil {
+			node.rewards = make(map[int]float64)
+		}
+		for id, r := range rewards {
+			node.rewards[id] += r
+		}
+	}
+	return true
+}
+
+// selectChild returns the child maximising UCT for the player to move at n.
+func (n *mctsNode) selectChild() *mctsNode {
+	var best *mctsNode
+	bestValue := math.Inf(-1)
+	for _, child := range n.children {
+		value := child.rewards[n.pid]/float64(child.visits) +
+			mctsExploration*math.Sqrt(math.Log(float64(n.visits))/float64(child.visits))
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// rollout plays sim forward with a noisy heuristic bot until the round after
+// turn ends or the game is over, and returns the resulting reward of each player.
+func (b *MCTSBot) rollout(sim *Game, turn int) map[int]float64 {
+	bot := HeuristicBot{}
+	for steps := 0; steps < mctsRolloutSteps && sim.Turn <= turn && !sim.gameOver(); steps++ {
+		if len(sim.CPUserIndices) == 0 {
+			break
+		}
+		pid := sim.CPUserIndices[0]
+
+		var cmd Command
+		if b.rand.Float64() < mctsEpsilon {
+			if cmds := botActions(sim, pid); len(cmds) > 0 {
+				cmd = cmds[b.rand.Intn(len(cmds))]
+			}
+		} else {
+			cmd = bot.Choose(sim, pid)
+		}
+		if cmd == nil {
+			break
+		}
+		if _, err := sim.Apply(pid, cmd); err != nil {
+			break
+		}
+	}
+	return rewardsFor(sim)
+}
+
+// rewardsFor rates the position of each player between 0 and 1 by comparing
+// its worth with that of its best rival.
+func rewardsFor(g *Game) map[int]float64 {
+	worths := make(map[int]int)
+	for _, p := range g.Players() {
+		worths[p.ID()] = worth(p)
+	}
+
+	rewards := make(map[int]float64)
+	for id, w := range worths {
+		rival := math.MinInt32
+		for oid, ow := range worths {
+			if oid != id && ow > rival {
+				rival = ow
+			}
+		}
+		rewards[id] = 1 / (1 + math.Exp(-float64(w-rival)/100))
+	}
+	return rewards
+}
+
+// worth estimates the final score of p as its rupiah plus the value of its companies.
+func worth(p *Player) int {
+	w := p.Score()
+	for _, c := range p.Companies() {
+		w += size(c) * c.Goods().Price()
+	}
+	return w
+}
+
+// rankedActions returns the botActions of the player having id pid,
+// best rated first, limited to mctsBranching.
+// Manual deliveries are left out while a delivery plan may be accepted,
+// since the plans already include the most profitable deliveries.
+func rankedActions(g *Game, pid int) []Command {
+	cmds := botActions(g, pid)
+	if g.allows(pid, AcceptProposedFlow{}) {
+		var plans []Command
+		for _, cmd := range cmds {
+			switch cmd.(type) {
+			case AcceptProposedFlow, AcceptAlternativeFlow:
+				plans = append(plans, cmd)
+			}
+		}
+		cmds = plans
+	}
+	p := g.PlayerByID(pid)
+	scores := make([]int, len(cmds))
+	for i, cmd := range cmds {
+		scores[i] = scoreAction(g, p, cmd)
+	}
+
+	index := make([]int, len(cmds))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool { return scores[index[i]] > scores[index[j]] })
+
+	if len(index) > mctsBranching {
+		index = index[:mctsBranching]
+	}
+	ranked := make([]Command, len(index))
+	for i, j := range index {
+		ranked[i] = cmds[j]
+	}
+	return ranked
+}
+
+func (g *Game) gameOver() bool {
+	return g.Status == game.Completed
+}
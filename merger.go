@@ -1,26 +1,22 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
-	"strconv"
 
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(announceMergerEntry))
-	gob.Register(new(mergerBidEntry))
-	gob.Register(new(mergerResolutionEntry))
-	gob.Register(new(removeRiceSpiceEntry))
+	registerType(new(announceMergerEntry))
+	registerType(new(mergerBidEntry))
+	registerType(new(mergerResolutionEntry))
+	registerType(new(removeRiceSpiceEntry))
 }
 
-func (g *Game) startMergers(c *gin.Context, cu *user.User) {
+func (g *Game) startMergers() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -39,8 +35,8 @@ func (g *Game) startMergers(c *gin.Context, cu *user.User) {
 	cp := g.CurrentPlayer()
 	if !cp.CanAnnounceMerger() {
 		g.autoPass(cp)
-		if np := g.mergersNextPlayer(cu); np == nil {
-			g.startAcquisitions(c, cu)
+		if np := g.mergersNextPlayer(); np == nil {
+			g.startAcquisitions()
 		} else {
 			g.setCurrentPlayers(np)
 			if g.SubPhase == MSiapFajiCreation {
@@ -51,16 +47,15 @@ func (g *Game) startMergers(c *gin.Context, cu *user.User) {
 	}
 }
 
-func (g *Game) selectCompany1(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) selectCompany1(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.validateSelectCompany1(c, cu)
+	com, err := g.validateSelectCompany1(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
 
-	cp := g.CurrentPlayer()
 	g.beginningOfPhaseReset()
 	g.Merger = newMerger(g)
 	g.Merger.setCompany1(com)
@@ -68,18 +63,18 @@ func (g *Game) selectCompany1(c *gin.Context, cu *user.User) (string, error) {
 
 	// Log
 	e := g.newAnnounceMergerEntryFor(cp, com)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	g.emit(e)
 
 	// Next SubPhase
 	g.SubPhase = MSelectCompany2
 	return "indonesia/announce_merger1_update", nil
 }
 
-func (g *Game) validateSelectCompany1(c *gin.Context, cu *user.User) (*Company, error) {
+func (g *Game) validateSelectCompany1(cp *Player) (*Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.SelectedCompany(), g.validatePlayerAction(cu)
+	com, err := g.SelectedCompany(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, err
@@ -139,27 +134,27 @@ func (e *announceMergerEntry) HTML(c *gin.Context) (s template.HTML) {
 	return
 }
 
-func (g *Game) selectCompany2(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) selectCompany2(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.validateSelectCompany2(c, cu)
+	com, err := g.validateSelectCompany2(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
 
-	e := g.CurrentPlayer().updateAnnounceMergerEntry(com)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := cp.updateAnnounceMergerEntry(com)
+	g.emit(e)
 	g.SubPhase = MBid
 	g.Merger.setCompany2(com)
 	return "indonesia/announce_merger2_update", nil
 }
 
-func (g *Game) validateSelectCompany2(c *gin.Context, cu *user.User) (*Company, error) {
+func (g *Game) validateSelectCompany2(cp *Player) (*Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.SelectedCompany(), g.validatePlayerAction(cu)
+	com, err := g.SelectedCompany(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, err
@@ -174,17 +169,15 @@ func (g *Game) validateSelectCompany2(c *gin.Context, cu *user.User) (*Company,
 	}
 }
 
-func (g *Game) mergerBid(c *gin.Context, cu *user.User) (tmpl string, act game.ActionType, err error) {
+func (g *Game) mergerBid(cp *Player, bid int) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	var bid int
-	if bid, err = g.validateMergerBid(c, cu); err != nil {
-		tmpl, act = "indonesia/flash_notice", game.None
+	if err = g.validateMergerBid(cp, bid); err != nil {
+		tmpl = "indonesia/flash_notice"
 		return
 	}
 
-	cp := g.CurrentPlayer()
 	if bid != NoBid {
 		g.Merger.setBid(cp, bid)
 	} else {
@@ -193,8 +186,8 @@ func (g *Game) mergerBid(c *gin.Context, cu *user.User) (tmpl string, act game.A
 	cp.PerformedAction = true
 
 	e := g.newMergerBidEntryFor(cp, bid)
-	restful.AddNoticef(c, string(e.HTML(c)))
-	tmpl, act = "indonesia/merger_bid_update", game.Cache
+	g.emit(e)
+	tmpl = "indonesia/merger_bid_update"
 	return
 }
 
@@ -202,21 +195,19 @@ func (p *Player) CanBidNone() bool {
 	return !(p.Game().Merger.AnnouncerID == p.ID() && p.Game().Merger.CurrentBid == 0)
 }
 
-func (g *Game) validateMergerBid(c *gin.Context, cu *user.User) (bid int, err error) {
+func (g *Game) validateMergerBid(cp *Player, bid int) (err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	bid = NoBid
-	if err = g.validatePlayerAction(cu); err != nil {
+	if err = g.validatePlayerAction(cp); err != nil {
 		return
 	}
 
-	cp := g.CurrentPlayer()
-	if bidValue := c.PostForm("bid"); bidValue == "none" {
+	if bid == NoBid {
 		if g.Merger.AnnouncerID == cp.ID() && g.Merger.CurrentBid == 0 {
 			err = sn.NewVError("You must bid at least the nominal value of Rp %d in order to announce the merger.", g.Merger.NominalBid())
 		}
-	} else if bid, err = strconv.Atoi(bidValue); err == nil {
+	} else {
 		switch {
 		case bid > cp.Rupiah:
 			err = sn.NewVError("You bid more than you have.")
@@ -275,6 +266,7 @@ type SiapFajiMerger struct {
 	OwnerID    int
 	OwnerSlot  int
 	Production int
+	removals   *removalSets
 }
 
 func (g *Game) newSiapFajiMerger(c *Company) {
@@ -554,20 +546,20 @@ func (p *Player) owns(c *Company) bool {
 	return p.ID() == c.OwnerID
 }
 
-func (g *Game) startMergerResolution(c *gin.Context, cu *user.User) {
+func (g *Game) startMergerResolution() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = Mergers
 	g.SubPhase = MResolution
-	g.payOwnersOf(c, g.Merger)
+	g.payOwnersOf(g.Merger)
 	c1Goods := g.Merger.Company1().Goods()
 	com := g.Merger.execute()
 
 	// If merged company is newly created Siap Faji company handle differently.
 	if c1Goods != SiapFaji && com.Goods() == SiapFaji {
 		g.newSiapFajiMerger(com)
-		g.siapFajiCreation(c)
+		g.siapFajiCreation()
 		if !g.SiapFajiMerger.CanEndRiceSpiceRemoval() {
 			return
 		}
@@ -580,14 +572,14 @@ func (g *Game) startMergerResolution(c *gin.Context, cu *user.User) {
 	g.setCurrentPlayers(announcer)
 	g.beginningOfPhaseReset()
 	g.SubPhase = MSelectCompany1
-	if np := g.mergersNextPlayer(cu); np != nil {
+	if np := g.mergersNextPlayer(); np != nil {
 		g.setCurrentPlayers(np)
 	} else {
-		g.startAcquisitions(c, cu)
+		g.startAcquisitions()
 	}
 }
 
-func (g *Game) payOwnersOf(c *gin.Context, m *Merger) {
+func (g *Game) payOwnersOf(m *Merger) {
 	bidder, owner1, owner2 := m.CurrentBidder(), m.Owner1(), m.Owner2()
 	bidder.Rupiah -= m.CurrentBid
 	r1, r2 := m.Owner1Share(), m.Owner2Share()
@@ -595,7 +587,7 @@ func (g *Game) payOwnersOf(c *gin.Context, m *Merger) {
 	owner2.Rupiah += r2
 
 	e := g.newMergerResolutionEntryFor(bidder, r1, r2)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	g.emit(e)
 }
 
 func (m *Merger) execute() *Company {
@@ -618,10 +610,9 @@ func (m *Merger) execute() *Company {
 	}
 
 	// Merge Zones/Areas
-	if slot.Company.IsProductionCompany() {
-		slot.Company.Zones = append(c1.Zones, c2.Zones...)
-	} else {
-		slot.Company.Zones = c1.Zones.addZones(c2.Zones...)
+	slot.Company.Zones = append(c1.Zones, c2.Zones...)
+	if slot.Company.IsShippingCompany() {
+		slot.Company.regroup()
 	}
 
 	// Update Areas
@@ -677,7 +668,7 @@ func (e *mergerResolutionEntry) HTML(c *gin.Context) (s template.HTML) {
 	return
 }
 
-func (g *Game) siapFajiCreation(c *gin.Context) {
+func (g *Game) siapFajiCreation() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -713,23 +704,22 @@ func (m *SiapFajiMerger) removeAreasAdjacentCompetitor() {
 	}
 }
 
-func (g *Game) removeRiceSpice(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) removeRiceSpice(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	m, a, err := g.validateRemoveRiceSpice(c, cu)
+	m, a, err := g.validateRemoveRiceSpice(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
 
-	cp := g.CurrentPlayer()
 	com := m.Company()
 	goods := a.Producer.Goods
 	com.remove(a)
 
 	// Log
 	e := g.newRemoveRiceSpiceEntryFor(cp, a, goods)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	g.emit(e)
 
 	// Return if more goods to remove
 	if !m.CanEndRiceSpiceRemoval() {
@@ -739,9 +729,7 @@ func (g *Game) removeRiceSpice(c *gin.Context, cu *user.User) (string, error) {
 	m.Company().toSiapFaji()
 
 	// Merge zones
-	if len(com.Zones) > 1 {
-		com.Zones = Zones{com.Zones[0]}.addZones(com.Zones[1:]...)
-	}
+	com.regroup()
 
 	// Reset game state for next merger round.
 	cp.PerformedAction = true
@@ -754,11 +742,11 @@ func (comp *Company) toSiapFaji() {
 	}
 }
 
-func (g *Game) validateRemoveRiceSpice(c *gin.Context, cu *user.User) (*SiapFajiMerger, *Area, error) {
+func (g *Game) validateRemoveRiceSpice(cp *Player) (*SiapFajiMerger, *Area, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	m, a, err := g.SiapFajiMerger, g.SelectedArea(), g.validatePlayerAction(cu)
+	m, a, err := g.SiapFajiMerger, g.SelectedArea(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, err
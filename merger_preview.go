@@ -0,0 +1,178 @@
+package indonesia
+
+import (
+	"net/http"
+
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+	"github.com/gin-gonic/gin"
+)
+
+// MergerPreview describes a merger a player may announce: the bids it allows,
+// what the owners of the merging companies receive at each bid, and the
+// company it creates.
+type MergerPreview struct {
+	Owner1ID     int            `json:"owner1ID"`
+	Owner1Slot   int            `json:"owner1Slot"`
+	Owner2ID     int            `json:"owner2ID"`
+	Owner2Slot   int            `json:"owner2Slot"`
+	NominalBid   int            `json:"nominalBid"`
+	BidIncrement int            `json:"bidIncrement"`
+	Payouts      []MergerPayout `json:"payouts"`
+
+	// Goods, Production, Ships and MaxShips describe the merged company.
+	// For a merger creating Siap Faji, Production is that of the company after
+	// the removal of rice and spice, Removed lists the areas removed for
+	// bordering competing companies, and GoodsToRemove counts the goods the
+	// owner must still remove.
+	Goods         Goods   `json:"goods"`
+	SiapFaji      bool    `json:"siapFaji"`
+	Production    int     `json:"production"`
+	Ships         int     `json:"ships"`
+	MaxShips      int     `json:"maxShips"`
+	Removed       AreaIDS `json:"removed,omitempty"`
+	GoodsToRemove int     `json:"goodsToRemove"`
+}
+
+// MergerPayout gives the rupiah paid to the owners of the merging companies
+// when the merged company is bought for Bid.
+type MergerPayout struct {
+	Bid         int `json:"bid"`
+	Owner1Share int `json:"owner1Share"`
+	Owner2Share int `json:"owner2Share"`
+}
+
+// MergerPreviews returns a preview of each merger the player having id pid may announce.
+func (g *Game) MergerPreviews(pid int) []*MergerPreview {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	p := g.PlayerByID(pid)
+	if p == nil {
+		return nil
+	}
+
+	var previews []*MergerPreview
+	cmap := mergeableCompaniesFor(p)
+	for _, c1 := range g.Companies() {
+		for _, c2 := range cmap[c1] {
+			if preview, err := g.PreviewMerger(pid, c1, c2); err == nil {
+				previews = append(previews, preview)
+			}
+		}
+	}
+	return previews
+}
+
+// PreviewMerger previews the merger of c1 and c2 announced by the player having id pid.
+// The merged company is found by executing the merger on a clone of the game.
+func (g *Game) PreviewMerger(pid int, c1, c2 *Company) (*MergerPreview, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	p := g.PlayerByID(pid)
+	switch {
+	case p == nil:
+		return nil, sn.NewVError("Player not found.")
+	case c1 == nil || c2 == nil:
+		return nil, sn.NewVError("Missing company selection.")
+	case !mergeableCompaniesFor(p)[c1].include(c2):
+		return nil, sn.NewVError("You can not merge the selected companies.")
+	}
+
+	m := newMerger(g)
+	m.setCompany1(c1)
+	m.setCompany2(c2)
+	if m.BidIncrement() <= 0 {
+		return nil, sn.NewVError("The selected companies have nothing to merge.")
+	}
+
+	preview := &MergerPreview{
+		Owner1ID:     c1.OwnerID,
+		Owner1Slot:   c1.Slot,
+		Owner2ID:     c2.OwnerID,
+		Owner2Slot:   c2.Slot,
+		NominalBid:   m.NominalBid(),
+		BidIncrement: m.BidIncrement(),
+	}
+
+	for bid := preview.NominalBid; bid <= m.maxBid(p); bid += preview.BidIncrement {
+		m.CurrentBid = bid
+		preview.Payouts = append(preview.Payouts, MergerPayout{
+			Bid:         bid,
+			Owner1Share: m.Owner1Share(),
+			Owner2Share: m.Owner2Share(),
+		})
+	}
+
+	cg, err := g.Clone()
+	if err != nil {
+		return nil, err
+	}
+	cm := newMerger(cg)
+	cm.setCompany1(c1)
+	cm.setCompany2(c2)
+	cm.setBid(cg.PlayerByID(pid), preview.NominalBid)
+	com := cm.execute()
+
+	preview.Goods = com.Goods()
+	preview.Production = com.Production()
+	preview.Ships = com.Ships()
+	if com.IsShippingCompany() {
+		preview.MaxShips = com.MaxShips()
+	}
+
+	if c1.Goods() != SiapFaji && com.Goods() == SiapFaji {
+		before := com.Areas()
+		cg.newSiapFajiMerger(com)
+		cg.SiapFajiMerger.removeAreasAdjacentCompetitor()
+		for _, a := range before {
+			if !com.Areas().include(a) {
+				preview.Removed = append(preview.Removed, a.ID)
+			}
+		}
+		preview.SiapFaji = true
+		preview.Production = cg.SiapFajiMerger.Production
+		preview.GoodsToRemove = max(cg.SiapFajiMerger.GoodsToRemove(), 0)
+	}
+	return preview, nil
+}
+
+// maxBid returns the most rupiah held by a player able to buy the merged
+// company, the announcer p included.
+func (m *Merger) maxBid(p *Player) int {
+	c1, c2 := m.Company1(), m.Company2()
+	most := p.Rupiah
+	for _, bidder := range m.g.Players() {
+		if bidder.hasEmptySlot() || bidder.owns(c1) || bidder.owns(c2) {
+			most = max(most, bidder.Rupiah)
+		}
+	}
+	return most
+}
+
+// mergerPreviews responds with previews of the mergers the current user may announce.
+func (client *Client) mergerPreviews(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g := gameFrom(c)
+	if g == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	cu, err := client.User.Current(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	pid := g.playerIDFor(cu)
+	if g.PlayerByID(pid) == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only players may preview mergers."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mergers": g.MergerPreviews(pid)})
+}
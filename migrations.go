@@ -0,0 +1,115 @@
+package indonesia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/log"
+)
+
+// CurrentSchemaVersion is the schema version of the state written by this code.
+// Each bump adds the migration from the previous version to migrations.
+const CurrentSchemaVersion = 2
+
+// migration upgrades a game from schema version From to From+1.
+type migration struct {
+	From    int
+	Summary string
+	Migrate func(*Game) error
+}
+
+// migrations lists the migrations in order.  States saved before schema
+// versions were recorded decode as version 0.
+var migrations = []migration{
+	{0, "Order the zones of each company, and the areas of each zone, by area id.", migrateZoneOrder},
+	{1, "Store the journal in record pages apart from the saved state.", migrateRecordPages},
+}
+
+func init() {
+	if len(migrations) != CurrentSchemaVersion {
+		panic(fmt.Sprintf("%d migrations for schema version %d", len(migrations), CurrentSchemaVersion))
+	}
+	for i, m := range migrations {
+		if m.From != i {
+			panic(fmt.Sprintf("migration %d upgrades from schema version %d", i, m.From))
+		}
+	}
+}
+
+// migrate upgrades g to the current schema version, reporting whether it changed.
+// Migrations run once the state is decoded and initialized.
+func (g *Game) migrate() (bool, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	from := g.SchemaVersion
+	if from > CurrentSchemaVersion {
+		return false, fmt.Errorf("state has schema version %d; version %d or earlier is required", from, CurrentSchemaVersion)
+	}
+
+	for _, m := range migrations[from:] {
+		if err := m.Migrate(g); err != nil {
+			return false, fmt.Errorf("migrating from schema version %d: %v", m.From, err)
+		}
+		g.SchemaVersion = m.From + 1
+	}
+	return g.SchemaVersion != from, nil
+}
+
+// migrateZoneOrder orders zones as the zone forest keeps them.  Zones saved
+// before the forest are in the order their areas were acquired.
+func migrateZoneOrder(g *Game) error {
+	for _, com := range g.Companies() {
+		com.zoneForest().rezone()
+	}
+	return nil
+}
+
+// migrateRecordPages leaves the journal decoded from a saved state of version 1
+// in place.  No records of the game are stored yet, so the next save stores
+// each in record pages and leaves the journal out of the saved state.
+func migrateRecordPages(g *Game) error {
+	g.Recorded = 0
+	return nil
+}
+
+// errDryRun aborts the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// GameKeys returns the keys of the games held by s.
+func GameKeys(c context.Context, s GameStore) ([]*datastore.Key, error) {
+	return s.Keys(c, kind, pk(nil))
+}
+
+// MigrateGame upgrades the game stored under k to the current schema version,
+// returning the schema version it had.  Unless dryRun, an upgraded game is saved.
+func MigrateGame(c context.Context, s GameStore, k *datastore.Key, dryRun bool) (int, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	var from int
+	err := s.RunInTransaction(c, func(tx StoreTx) error {
+		g, err := getStored(tx, k)
+		if err != nil {
+			return err
+		}
+
+		from = g.SchemaVersion
+		switch changed, err := g.migrate(); {
+		case err != nil:
+			return err
+		case !changed:
+			return nil
+		case dryRun:
+			return errDryRun
+		}
+
+		return putStored(tx, g)
+	})
+	if err == errDryRun {
+		err = nil
+	}
+	return from, err
+}
@@ -1,24 +1,22 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 
-	"github.com/SlothNinja/contest"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(noNewEraEntry))
-	gob.Register(new(newEraEntry))
-	gob.Register(new(endGameTriggeredEntry))
+	registerType(new(noNewEraEntry))
+	registerType(new(newEraEntry))
+	registerType(new(endGameTriggeredEntry))
 }
 
-func (client *Client) startNewEra(c *gin.Context, g *Game) ([]*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) startNewEra() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
 	g.Phase = NewEra
 	g.Turn += 1
@@ -26,7 +24,7 @@ func (client *Client) startNewEra(c *gin.Context, g *Game) ([]*contest.Contest,
 	g.beginningOfPhaseReset()
 	g.resetCompanies()
 	g.resetCities()
-	return client.checkForNewEra(c, g)
+	g.checkForNewEra()
 }
 
 //func (g *Game) beginningOfTurnReset() {
@@ -36,9 +34,9 @@ func (client *Client) startNewEra(c *gin.Context, g *Game) ([]*contest.Contest,
 //	}
 //}
 
-func (client *Client) checkForNewEra(c *gin.Context, g *Game) ([]*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) checkForNewEra() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
 	g.AvailableDeeds = g.AvailableDeeds.RemoveUnstartable(g)
 	switch n := g.AvailableDeeds.Types(); {
@@ -46,21 +44,37 @@ func (client *Client) checkForNewEra(c *gin.Context, g *Game) ([]*contest.Contes
 		g.Era += 1
 		g.newNewEraEntry(n, g.Era, g.AvailableDeeds)
 		g.AvailableDeeds = deedsFor(g.Era).RemoveUnstartable(g)
-		g.startNewCity(c)
-		return nil, nil
+		g.startNewCity()
 	case n < 2 && g.Era == EraC:
 		g.newEndGameTriggeredEntry(n)
-		return client.endGame(c, g)
+		g.endGame()
 	default:
 		g.newNoNewEraEntry(n, g.Era)
-		g.startBidForTurnOrder(c)
-		return nil, nil
+		g.startBidForTurnOrder()
 	}
 }
 
-func (g *Game) startNewCity(c *gin.Context) {
+// startNewCity hands the turn to the first player able to place a city of the new era.
+func (g *Game) startNewCity() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
+
+	for _, p := range g.Players() {
+		p.resetCache()
+	}
+
+	for _, p := range g.Players() {
+		if p.CanPlaceCity() {
+			p.beginningOfTurnReset()
+			g.setCurrentPlayers(p)
+			return
+		}
+	}
+
+	for _, p := range g.Players() {
+		g.removeUnplayableCityCardsFor(p)
+	}
+	g.startBidForTurnOrder()
 }
 
 type newEraEntry struct {
@@ -1,26 +1,22 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 
-	"github.com/SlothNinja/contest"
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(selectCompanyEntry))
-	gob.Register(new(deliveredGoodEntry))
-	gob.Register(new(receiveIncomeEntry))
-	gob.Register(make(ShipperIncomeMap, 0))
-	gob.Register(new(expandProductionEntry))
-	gob.Register(new(expandShippingEntry))
-	gob.Register(new(stopExpandingEntry))
+	registerType(new(selectCompanyEntry))
+	registerType(new(deliveredGoodEntry))
+	registerType(new(receiveIncomeEntry))
+	registerType(make(ShipperIncomeMap, 0))
+	registerType(new(expandProductionEntry))
+	registerType(new(expandShippingEntry))
+	registerType(new(stopExpandingEntry))
 }
 
 type ShipperIncomeMap map[int]int
@@ -45,13 +41,14 @@ func (m ShipperIncomeMap) OwnShips(pid int) int {
 	return ships
 }
 
-func (client *Client) startOperations(c *gin.Context, g *Game, cu *user.User) ([]*contest.Contest, error) {
-	client.Log.Debugf(msgEnter)
-	defer client.Log.Debugf(msgExit)
+func (g *Game) startOperations() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
 
-	np := g.companyExpansionNextPlayer(cu)
+	np := g.companyExpansionNextPlayer()
 	if np == nil {
-		return client.startCityGrowth(c, g)
+		g.startCityGrowth()
+		return
 	}
 
 	g.beginningOfPhaseReset()
@@ -61,7 +58,6 @@ func (client *Client) startOperations(c *gin.Context, g *Game, cu *user.User) ([
 	g.resetOpIncome()
 	g.setCurrentPlayers(np)
 	g.OverrideDeliveries = -1
-	return nil, nil
 }
 
 func (g *Game) resetOpIncome() {
@@ -70,52 +66,53 @@ func (g *Game) resetOpIncome() {
 	}
 }
 
-func (g *Game) selectCompany(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) selectCompany(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.validateSelectCompany(c, cu)
+	com, err := g.validateSelectCompany(cp)
 	switch {
 	case err != nil:
 		return "indonesia/flash_notice", err
 	case com.IsShippingCompany():
 		g.SubPhase = OPFreeExpansion
-		if cp := g.CurrentPlayer(); cp.canExpandShipping() {
+		if cp.canExpandShipping() {
 
 			// Log
-			e := g.newSelectCompanyEntryFor(g.CurrentPlayer(), com, 0)
-			restful.AddNoticef(c, string(e.HTML(c)))
+			e := g.newSelectCompanyEntryFor(cp, com, 0)
+			g.emit(e)
 			return "indonesia/select_company_update", nil
 		} else {
 			cp.PerformedAction = true
 			com.Operated = true
-			e := g.newSelectCompanyEntryFor(g.CurrentPlayer(), com, 0)
-			restful.AddNoticef(c, string(e.HTML(c)))
+			e := g.newSelectCompanyEntryFor(cp, com, 0)
+			g.emit(e)
 			return "indonesia/completed_expansion_dialog", nil
 		}
 	default:
-		e := g.newSelectCompanyEntryFor(g.CurrentPlayer(), com, 0)
-		restful.AddNoticef(c, string(e.HTML(c)))
+		e := g.newSelectCompanyEntryFor(cp, com, 0)
+		g.emit(e)
 		if g.OverrideDeliveries > -1 {
 			g.RequiredDeliveries = g.OverrideDeliveries
 		} else {
 			g.RequiredDeliveries, g.ProposedPath = com.maxFlow()
+			g.AlternativePaths = com.alternativeFlows(g.ProposedPath)
 		}
 		if g.RequiredDeliveries > 0 {
 			g.SubPhase = OPSelectProductionArea
 			g.ShipperIncomeMap = make(ShipperIncomeMap, 0)
 			return "indonesia/select_company_update", nil
 		} else {
-			return g.startCompanyExpansion(c), nil
+			return g.startCompanyExpansion(), nil
 		}
 	}
 }
 
-func (g *Game) validateSelectCompany(c *gin.Context, cu *user.User) (*Company, error) {
+func (g *Game) validateSelectCompany(cp *Player) (*Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.SelectedCompany(), g.validatePlayerAction(cu)
+	com, err := g.SelectedCompany(), g.validatePlayerAction(cp)
 	if err != nil {
 		return nil, err
 	}
@@ -149,12 +146,12 @@ func (e *selectCompanyEntry) HTML(c *gin.Context) template.HTML {
 	return restful.HTML("<div>%s selected the %s company to operate.</div>", name, company.String())
 }
 
-func (g *Game) selectGood(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) selectGood(cp *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	var a *Area
-	if a, err = g.validateSelectGood(c, cu); err != nil {
+	if a, err = g.validateSelectGood(cp); err != nil {
 		tmpl = "indonesia/flash_notice"
 		return
 	}
@@ -193,28 +190,30 @@ func (fp flowMatrix) addFlow(source, target FlowID) flowMatrix {
 	return flowPath
 }
 
-func (g *Game) validateSelectGood(c *gin.Context, cu *user.User) (*Area, error) {
+func (g *Game) validateSelectGood(cp *Player) (*Area, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(cp)
 	if err != nil {
 		return nil, err
 	}
 
 	com := g.SelectedCompany()
 	a := g.SelectedArea()
-	zone := com.ZoneFor(a)
 
 	switch {
 	case com == nil:
 		return nil, sn.NewVError("You must select company to operate.")
 	case a == nil:
 		return nil, sn.NewVError("You must select a good area.")
-	case zone == nil:
+	case com.ZoneFor(a) == nil:
 		return nil, sn.NewVError("You must select a good in a production zone of the company.")
 	case a.Used:
 		return nil, sn.NewVError("The selected area has already delivered its goods.")
+	case !com.canDeliver(g.RequiredDeliveries-com.Delivered(), toFlowID(com.ZoneFor(a).AreaIDS[0]), noFID, NoArea):
+		return nil, sn.NewVError("The selected good can not reach a city without leaving you short of the %d required deliveries.",
+			g.RequiredDeliveries)
 	default:
 		return a, nil
 	}
@@ -222,7 +221,7 @@ func (g *Game) validateSelectGood(c *gin.Context, cu *user.User) (*Area, error)
 
 const InvalidUsedShips = -1
 
-func (g *Game) selectShip(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) selectShip(cp *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -232,7 +231,7 @@ func (g *Game) selectShip(c *gin.Context, cu *user.User) (tmpl string, err error
 		incomeMap ShipperIncomeMap
 	)
 
-	if old, area, shipper, incomeMap, err = g.validateSelectShip(c, cu); err != nil {
+	if old, area, shipper, incomeMap, err = g.validateSelectShip(cp); err != nil {
 		tmpl = "indonesia/flash_notice"
 		return
 	}
@@ -286,7 +285,7 @@ func (g *Game) selectShip(c *gin.Context, cu *user.User) (tmpl string, err error
 	return
 }
 
-func (g *Game) validateSelectShip(c *gin.Context, cu *user.User) (*Area, *Area, *Shipper, ShipperIncomeMap, error) {
+func (g *Game) validateSelectShip(cp *Player) (*Area, *Area, *Shipper, ShipperIncomeMap, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -296,7 +295,7 @@ func (g *Game) validateSelectShip(c *gin.Context, cu *user.User) (*Area, *Area,
 	old, area := g.SelectedArea(), g.SelectedArea2()
 	incomeMap := g.ShipperIncomeMap
 
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, nil, nil, err
@@ -305,7 +304,7 @@ func (g *Game) validateSelectShip(c *gin.Context, cu *user.User) (*Area, *Area,
 	case !(g.SubPhase == OPSelectShip || g.SubPhase == OPSelectCityOrShip):
 		return nil, nil, nil, nil, sn.NewVError("Expected %q or %q subphase, have %q subphase.",
 			SubPhaseNames[OPSelectShip], SubPhaseNames[OPSelectCityOrShip], g.SubPhaseName())
-	case c == nil:
+	case com == nil:
 		return nil, nil, nil, nil, sn.NewVError("You must select company to operate.")
 	case g.ShipperIncomeMap == nil:
 		return nil, nil, nil, nil, sn.NewVError("Missing temp value for income map.")
@@ -318,33 +317,36 @@ func (g *Game) validateSelectShip(c *gin.Context, cu *user.User) (*Area, *Area,
 		return nil, nil, nil, nil, sn.NewVError("The selected ship has already reached its hull limit.")
 	case shippingCompany != nil && !(shippingCompany.OwnerID == shipper.OwnerID && shippingCompany.Slot == shipper.Slot):
 		return nil, nil, nil, nil, sn.NewVError("You must select a ship of the same shipping company.")
+	case !g.canDeliverBoarding(com, area, shipper):
+		return nil, nil, nil, nil, sn.NewVError("The selected ship would leave you short of the %d required deliveries.",
+			g.RequiredDeliveries)
 	default:
 		return old, area, shipper, incomeMap, nil
 	}
 }
 
-func (g *Game) selectCityOrShip(c *gin.Context, cu *user.User) (string, error) {
-	log.Debugf(msgEnter)
-	defer log.Debugf(msgExit)
-
-	area2 := g.SelectedArea2()
-	switch {
-	case area2 == nil:
-		return "indonesia/flash_notice", sn.NewVError("You must select an area having a city or boat.")
-	case area2.IsLand():
-		return g.selectCity(c, cu)
-	case area2.IsSea():
-		return g.selectShip(c, cu)
-	default:
-		return "indonesia/flash_notice", sn.NewVError("Unexpectant value for area received.")
+// canDeliverBoarding reports whether com can still make its required deliveries once
+// the good being delivered boards shipper in area.
+func (g *Game) canDeliverBoarding(com *Company, area *Area, shipper *Shipper) bool {
+	input := FlowID{
+		AreaID:   area.ID,
+		PID:      shipper.OwnerID,
+		Index:    g.SelectedShipperIndex,
+		IO:       shipInput,
+		Province: shipper.Province(),
 	}
+	output := input
+	output.IO = shipOutput
+
+	// The good being delivered has already been counted by com.Delivered.
+	return com.canDeliver(g.RequiredDeliveries-com.Delivered()+1, output, input, NoArea)
 }
 
-func (g *Game) selectCity(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) selectCity(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	city, company, from, to, shippingCompany, used, err := g.validateSelectCity(c, cu)
+	city, company, from, to, shippingCompany, used, err := g.validateSelectCity(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
@@ -363,10 +365,10 @@ func (g *Game) selectCity(c *gin.Context, cu *user.User) (string, error) {
 	g.CustomPath = g.CustomPath.addFlow(inputFID, outputFID)
 
 	// Log
-	e := g.newDeliveredGoodEntryFor(g.CurrentPlayer(), company.Goods(), from, to, shippingCompany.OwnerID, used)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := g.newDeliveredGoodEntryFor(cp, company.Goods(), from, to, shippingCompany.OwnerID, used)
+	g.emit(e)
 	if company.Delivered() == g.RequiredDeliveries {
-		return g.receiveIncome(c, cu)
+		return g.receiveIncome(cp)
 	}
 
 	g.SubPhase = OPSelectProductionArea
@@ -378,8 +380,8 @@ func (g *Game) resetShipper() {
 	g.ShippingCompanyOwnerID, g.ShippingCompanySlot, g.ShipsUsed = NoPlayerID, NoSlot, InvalidUsedShips
 }
 
-// func (g *Game) validateSelectCity(c *gin.Context) (city *City, c *Company, from Province, to Province, sc *Company, used int, err error) {
-func (g *Game) validateSelectCity(c *gin.Context, cu *user.User) (*City, *Company, Province, Province, *Company, int, error) {
+// func (g *Game) validateSelectCity(cp *Player) (city *City, c *Company, from Province, to Province, sc *Company, used int, err error) {
+func (g *Game) validateSelectCity(cp *Player) (*City, *Company, Province, Province, *Company, int, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -388,7 +390,7 @@ func (g *Game) validateSelectCity(c *gin.Context, cu *user.User) (*City, *Compan
 	a2 := g.SelectedArea2()
 	goodsArea := g.SelectedGoodsArea()
 
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, 0, 0, nil, 0, err
@@ -396,7 +398,7 @@ func (g *Game) validateSelectCity(c *gin.Context, cu *user.User) (*City, *Compan
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("Expected %q phase, have %q phase.", PhaseNames[Operations], g.PhaseName())
 	case g.SubPhase != OPSelectCityOrShip:
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("Expected %q subphase, have %q subphase.", SubPhaseNames[OPSelectCityOrShip], g.SubPhaseName())
-	case c == nil:
+	case com == nil:
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("You must select company to operate.")
 	case goodsArea == nil:
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("Missing selected goods area.")
@@ -412,8 +414,10 @@ func (g *Game) validateSelectCity(c *gin.Context, cu *user.User) (*City, *Compan
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("Missing temp value for used ships.")
 	case sc == nil:
 		return nil, nil, 0, 0, nil, 0, sn.NewVError("Missing temp value for shipping company owner.")
+	case !com.canDeliver(g.RequiredDeliveries-com.Delivered(), noFID, noFID, a2.ID):
+		return nil, nil, 0, 0, nil, 0, sn.NewVError("Delivering to the selected city would leave you short of the %d required deliveries.",
+			g.RequiredDeliveries)
 	default:
-		// func (g *Game) validateSelectCity(c *gin.Context) (city *City, c *Company, from Province, to Province, sc *Company, used int, err error) {
 		return a2.City, com, goodsArea.Province(), a2.Province(), sc, g.ShipsUsed, nil
 	}
 }
@@ -445,24 +449,23 @@ func (e *deliveredGoodEntry) HTML(c *gin.Context) template.HTML {
 	return restful.HTML("<div>%s delivered %s from the %s province to the city in the %s province using %d ships of %s.</div>", g.NameByPID(e.PlayerID), e.Goods, e.From, e.To, e.ShipsUsed, g.NameByPID(e.OtherPlayerID))
 }
 
-func (g *Game) receiveIncome(c *gin.Context, cu *user.User) (string, error) {
+func (g *Game) receiveIncome(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.SubPhase = OPReceiveIncome
-	com, incomeMap, err := g.validateReceiveIncome(c, cu)
+	com, incomeMap, err := g.validateReceiveIncome(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
-	cp := g.CurrentPlayer()
 	otherShips := incomeMap.OtherShips(cp.ID())
-	income := com.Delivered()*com.Goods().Price() - (otherShips * 5)
+	income := com.Delivered()*com.Goods().Price() - (otherShips * shipFee)
 	cp.Rupiah += income
 	cp.OpIncome += income
 	if otherShips != 0 {
 		for pid, count := range incomeMap {
 			if pid != cp.ID() {
-				income := 5 * count
+				income := shipFee * count
 				p := g.PlayerByID(pid)
 				p.Rupiah += income
 				p.OpIncome += income
@@ -471,19 +474,19 @@ func (g *Game) receiveIncome(c *gin.Context, cu *user.User) (string, error) {
 	}
 
 	// Log
-	e := g.newReceiveIncomeEntryFor(g.CurrentPlayer(), com.Delivered(), com.Goods(), incomeMap)
-	restful.AddNoticef(c, string(e.HTML(c)))
-	return g.startCompanyExpansion(c), nil
+	e := g.newReceiveIncomeEntryFor(cp, com.Delivered(), com.Goods(), incomeMap)
+	g.emit(e)
+	return g.startCompanyExpansion(), nil
 }
 
-// func (g *Game) validateReceiveIncome(c *gin.Context) (c *Company, incomeMap ShipperIncomeMap, err error) {
-func (g *Game) validateReceiveIncome(c *gin.Context, cu *user.User) (*Company, ShipperIncomeMap, error) {
+// func (g *Game) validateReceiveIncome(cp *Player) (c *Company, incomeMap ShipperIncomeMap, err error) {
+func (g *Game) validateReceiveIncome(cp *Player) (*Company, ShipperIncomeMap, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	com := g.SelectedCompany()
 	incomeMap := g.ShipperIncomeMap
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, err
@@ -517,7 +520,7 @@ func (g *Game) newReceiveIncomeEntryFor(p *Player, delivered int, goods Goods, i
 
 func (e *receiveIncomeEntry) HTML(c *gin.Context) (s template.HTML) {
 	otherShips := e.ShipperIncome.OtherShips(e.PlayerID)
-	rupiah := e.Delivered*e.Goods.Price() - (otherShips * 5)
+	rupiah := e.Delivered*e.Goods.Price() - (otherShips * shipFee)
 	g := gameFrom(c)
 	s = restful.HTML("<div>%s received %d rupiah for selling %d %s (%d &times; %d %s - 5 &times; %d ships)</div>",
 		g.NameByPID(e.PlayerID), rupiah, e.Delivered, e.Goods, e.Goods.Price(), e.Delivered, e.Goods, otherShips)
@@ -525,14 +528,14 @@ func (e *receiveIncomeEntry) HTML(c *gin.Context) (s template.HTML) {
 		for pid, count := range e.ShipperIncome {
 			if pid != e.PlayerID {
 				s += restful.HTML("<div>%s received %d rupiah for %d ships used to transport %s.</div>",
-					g.NameByPID(pid), 5*count, count, e.Goods)
+					g.NameByPID(pid), shipFee*count, count, e.Goods)
 			}
 		}
 	}
 	return
 }
 
-func (g *Game) startCompanyExpansion(c *gin.Context) (tmpl string) {
+func (g *Game) startCompanyExpansion() (tmpl string) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -553,32 +556,31 @@ func (g *Game) startCompanyExpansion(c *gin.Context) (tmpl string) {
 	return
 }
 
-// func (g *Game) stopExpanding(c *gin.Context) (tmpl string, act game.ActionType, err error) {
-func (g *Game) stopExpanding(c *gin.Context, cu *user.User) (string, game.ActionType, error) {
+// func (g *Game) stopExpanding(cp *Player) (tmpl string, err error) {
+func (g *Game) stopExpanding(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.validateStopExpanding(c, cu)
+	com, err := g.validateStopExpanding(cp)
 	if err != nil {
-		return "indonesia/flash_notice", game.None, err
+		return "indonesia/flash_notice", err
 	}
 
-	cp := g.CurrentPlayer()
 	cp.PerformedAction = true
 	com.Operated = true
 
 	// Log
-	e := g.newStopExpandingEntryFor(g.CurrentPlayer())
-	restful.AddNoticef(c, string(e.HTML(c)))
-	return "indonesia/stop_expanding_update", game.Cache, nil
+	e := g.newStopExpandingEntryFor(cp)
+	g.emit(e)
+	return "indonesia/stop_expanding_update", nil
 }
 
-// func (g *Game) validateStopExpanding(c *gin.Context) (c *Company, err error) {
-func (g *Game) validateStopExpanding(c *gin.Context, cu *user.User) (*Company, error) {
+// func (g *Game) validateStopExpanding(cp *Player) (c *Company, err error) {
+func (g *Game) validateStopExpanding(cp *Player) (*Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.SelectedCompany(), g.validatePlayerAction(cu)
+	com, err := g.SelectedCompany(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, err
@@ -607,12 +609,12 @@ func (e *stopExpandingEntry) HTML(c *gin.Context) template.HTML {
 	return restful.HTML("<div>%s stopped expanding selected company.</div>", g.NameByPID(e.PlayerID))
 }
 
-// func (g *Game) expandProduction(c *gin.Context) (tmpl string, err error) {
-func (g *Game) expandProduction(c *gin.Context, cu *user.User) (string, error) {
+// func (g *Game) expandProduction(cp *Player) (tmpl string, err error) {
+func (g *Game) expandProduction(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	a, com, err := g.validateExpandProduction(c, cu)
+	a, com, err := g.validateExpandProduction(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
@@ -620,7 +622,6 @@ func (g *Game) expandProduction(c *gin.Context, cu *user.User) (string, error) {
 	g.Expansions += 1
 	a.AddProducer(com)
 	com.AddArea(a)
-	cp := g.CurrentPlayer()
 
 	// Log
 	if g.SubPhase == OPExpansion {
@@ -629,7 +630,7 @@ func (g *Game) expandProduction(c *gin.Context, cu *user.User) (string, error) {
 		cp.OpIncome -= expense
 	}
 	e := g.newExpandProductionEntryFor(cp, com.Goods(), a.Province(), g.SubPhase == OPFreeExpansion)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	g.emit(e)
 	if g.Expansions == g.RequiredExpansions || cp.RemainingExpansions() == 0 {
 		com.Operated = true
 		cp.PerformedAction = true
@@ -645,13 +646,12 @@ func (p *Player) RemainingExpansions() int {
 	return p.Technologies[ExpansionsTech] - p.Game().Expansions
 }
 
-// func (g *Game) validateExpandProduction(c *gin.Context) (a *Area, c *Company, err error) {
-func (g *Game) validateExpandProduction(c *gin.Context, cu *user.User) (*Area, *Company, error) {
+// func (g *Game) validateExpandProduction(cp *Player) (a *Area, c *Company, err error) {
+func (g *Game) validateExpandProduction(cp *Player) (*Area, *Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	cp := g.CurrentPlayer()
-	a, com, err := g.SelectedArea(), g.SelectedCompany(), g.validatePlayerAction(cu)
+	a, com, err := g.SelectedArea(), g.SelectedCompany(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, err
@@ -702,12 +702,12 @@ func (e *expandProductionEntry) HTML(c *gin.Context) (s template.HTML) {
 	return
 }
 
-// func (g *Game) expandShipping(c *gin.Context) (tmpl string, err error) {
-func (g *Game) expandShipping(c *gin.Context, cu *user.User) (string, error) {
+// func (g *Game) expandShipping(cp *Player) (tmpl string, err error) {
+func (g *Game) expandShipping(cp *Player) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	a, com, err := g.validateExpandShipping(c, cu)
+	a, com, err := g.validateExpandShipping(cp)
 	if err != nil {
 		return "indonesia/flash_notice", err
 	}
@@ -715,11 +715,10 @@ func (g *Game) expandShipping(c *gin.Context, cu *user.User) (string, error) {
 	g.Expansions += 1
 	com.Operated = true
 	com.AddShipIn(a)
-	cp := g.CurrentPlayer()
 
 	// Log
-	e := g.newExpandShippingEntryFor(g.CurrentPlayer(), com, a)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := g.newExpandShippingEntryFor(cp, com, a)
+	g.emit(e)
 	if g.Expansions < cp.Technologies[ExpansionsTech] && com.Ships() < com.MaxShips() {
 		return "indonesia/select_shipping_area_update", nil
 	}
@@ -727,17 +726,15 @@ func (g *Game) expandShipping(c *gin.Context, cu *user.User) (string, error) {
 	return "indonesia/completed_expansion_update", nil
 }
 
-// func (g *Game) validateExpandShipping(c *gin.Context) (a *Area, c *Company, err error) {
-func (g *Game) validateExpandShipping(c *gin.Context, cu *user.User) (*Area, *Company, error) {
+// func (g *Game) validateExpandShipping(cp *Player) (a *Area, c *Company, err error) {
+func (g *Game) validateExpandShipping(cp *Player) (*Area, *Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	a := g.SelectedArea()
 	com := g.SelectedCompany()
-	maxShips := com.MaxShips()
-	cp := g.CurrentPlayer()
 
-	err := g.validatePlayerAction(cu)
+	err := g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, nil, err
@@ -749,8 +746,8 @@ func (g *Game) validateExpandShipping(c *gin.Context, cu *user.User) (*Area, *Co
 		return nil, nil, sn.NewVError("Selected area is not a valid expansion area.")
 	case g.Expansions >= cp.Technologies[ExpansionsTech]:
 		return nil, nil, sn.NewVError("You have already performed the allotted number of expansion.")
-	case com.Ships() >= maxShips:
-		return nil, nil, sn.NewVError("The selected company is already at it's ship limit of %d for the era.", maxShips)
+	case com.Ships() >= com.MaxShips():
+		return nil, nil, sn.NewVError("The selected company is already at it's ship limit of %d for the era.", com.MaxShips())
 	case com.MaxShips() == com.Ships():
 		return nil, nil, sn.NewVError("The selected shipping company has already expanded to its ship limit for the era.")
 	default:
@@ -795,22 +792,54 @@ func (p *Player) CanFreeExpansion() bool {
 	return g.Phase == Operations && g.SubPhase == OPFreeExpansion
 }
 
-// func (g *Game) acceptProposedFlow(c *gin.Context) (tmpl string, act game.ActionType, err error) {
-func (g *Game) acceptProposedFlow(c *gin.Context, cu *user.User) (string, game.ActionType, error) {
+// func (g *Game) acceptProposedFlow(cp *Player) (tmpl string, err error) {
+func (g *Game) acceptProposedFlow(cp *Player) (string, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	com, err := g.validateAcceptProposedFlow(cp)
+	if err != nil {
+		return "indonesia/flash_notice", err
+	}
+	return g.deliverAlong(cp, com, g.ProposedPath)
+}
+
+// acceptAlternativeFlow delivers goods along the alternative delivery plan having index plan.
+func (g *Game) acceptAlternativeFlow(cp *Player, plan int) (string, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.validateAcceptProposedFlow(c, cu)
+	com, err := g.validateAcceptAlternativeFlow(cp, plan)
 	if err != nil {
-		return "indonesia/flash_notice", game.None, err
+		return "indonesia/flash_notice", err
+	}
+	return g.deliverAlong(cp, com, g.AlternativePaths[plan])
+}
+
+func (g *Game) validateAcceptAlternativeFlow(cp *Player, plan int) (*Company, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	com, err := g.validateAcceptProposedFlow(cp)
+	switch {
+	case err != nil:
+		return nil, err
+	case plan < 0 || plan >= len(g.AlternativePaths):
+		return nil, sn.NewVError("Select a valid delivery plan.")
+	default:
+		return com, nil
 	}
+}
+
+// deliverAlong delivers the goods of com along fm and pays the resulting income.
+func (g *Game) deliverAlong(cp *Player, com *Company, fm flowMatrix) (string, error) {
 	com.Operated = true
 
-	g.ShipperIncomeMap = g.ProposedShips(g.ProposedPath)
-	for aid, v := range g.ProposedCities() {
+	g.ShipperIncomeMap = fm.ships()
+	for aid, v := range fm.cities() {
 		g.GetArea(aid).City.Delivered[com.Goods()] += v
 	}
-	for fid, v := range g.ProposedPath[sourceFID] {
+	for fid, v := range fm[sourceFID] {
 		count := 0
 		for _, a := range com.ZoneFor(g.GetArea(fid.AreaID)).Areas() {
 			a.Used = true
@@ -820,19 +849,15 @@ func (g *Game) acceptProposedFlow(c *gin.Context, cu *user.User) (string, game.A
 		}
 	}
 
-	tmpl, err := g.receiveIncome(c, cu)
-	if err != nil {
-		return tmpl, game.None, err
-	}
-	return tmpl, game.Cache, nil
+	return g.receiveIncome(cp)
 }
 
-// func (g *Game) validateAcceptProposedFlow(c *gin.Context) (c *Company, err error) {
-func (g *Game) validateAcceptProposedFlow(c *gin.Context, cu *user.User) (*Company, error) {
+// func (g *Game) validateAcceptProposedFlow(cp *Player) (c *Company, err error) {
+func (g *Game) validateAcceptProposedFlow(cp *Player) (*Company, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	com, err := g.SelectedCompany(), g.validatePlayerAction(cu)
+	com, err := g.SelectedCompany(), g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
 		return nil, err
@@ -1,49 +1,45 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(passEntry))
-	gob.Register(new(autoPassEntry))
+	registerType(new(passEntry))
+	registerType(new(autoPassEntry))
 }
 
-func (g *Game) pass(c *gin.Context, cu *user.User) (tmpl string, act game.ActionType, err error) {
+func (g *Game) pass(p *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if err = g.validatePass(c, cu); err != nil {
+	if err = g.validatePass(p); err != nil {
 		log.Errorf(err.Error())
-		tmpl, act = "indonesia/flash_notice", game.None
+		tmpl = "indonesia/flash_notice"
 		return
 	}
 
-	cp := g.CurrentPlayer()
-	cp.Passed = true
-	cp.PerformedAction = true
+	p.Passed = true
+	p.PerformedAction = true
 
 	// Log Pass
-	e := g.newPassEntryFor(cp)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	e := g.newPassEntryFor(p)
+	g.emit(e)
 
-	tmpl, act = "indonesia/pass_update", game.Cache
+	tmpl = "indonesia/pass_update"
 	return
 }
 
-func (g *Game) validatePass(c *gin.Context, cu *user.User) (err error) {
+func (g *Game) validatePass(p *Player) (err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if err = g.validatePlayerAction(cu); err != nil {
+	if err = g.validatePlayerAction(p); err != nil {
 		return
 	}
 
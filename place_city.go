@@ -1,22 +1,20 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(placeCityEntry))
-	gob.Register(new(discardCityEntry))
+	registerType(new(placeCityEntry))
+	registerType(new(discardCityEntry))
 }
 
-func (g *Game) placeCity(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) placeCity(p *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
@@ -25,7 +23,7 @@ func (g *Game) placeCity(c *gin.Context, cu *user.User) (tmpl string, err error)
 		c0, c1 *CityCard
 	)
 
-	if a, c0, c1, err = g.validatePlaceCity(c, cu); err != nil {
+	if a, c0, c1, err = g.validatePlaceCity(p); err != nil {
 		tmpl = "indonesia/flash_notice"
 		return
 	}
@@ -39,7 +37,7 @@ func (g *Game) placeCity(c *gin.Context, cu *user.User) (tmpl string, err error)
 		cp.CityCards = cp.CityCards[1:]
 		// Log placement
 		e := g.newPlaceCityEntryFor(cp, c0)
-		restful.AddNoticef(c, string(e.HTML(c)))
+		g.emit(e)
 		tmpl = "indonesia/place_city_update"
 	case c0 == nil && c1 != nil:
 		a.City = newCity(a)
@@ -48,7 +46,7 @@ func (g *Game) placeCity(c *gin.Context, cu *user.User) (tmpl string, err error)
 		cp.CityCards = cp.CityCards[1:]
 		// Log placement
 		e := g.newPlaceCityEntryFor(cp, c1)
-		restful.AddNoticef(c, string(e.HTML(c)))
+		g.emit(e)
 		tmpl = "indonesia/place_city_update"
 	default:
 		cp.PerformedAction = false
@@ -76,15 +74,14 @@ func (p *Player) cardsFor(a *Area) (c0, c1 *CityCard) {
 	return
 }
 
-func (g *Game) validatePlaceCity(c *gin.Context, cu *user.User) (a *Area, c0, c1 *CityCard, err error) {
+func (g *Game) validatePlaceCity(p *Player) (a *Area, c0, c1 *CityCard, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	a = g.SelectedArea()
-	cp := g.CurrentPlayer()
-	c0, c1 = cp.cardsFor(a)
+	c0, c1 = p.cardsFor(a)
 
-	switch err = g.validatePlayerAction(cu); {
+	switch err = g.validatePlayerAction(p); {
 	case err != nil:
 	case a == nil:
 		err = sn.NewVError("You must select an area.")
@@ -120,13 +117,13 @@ func (e *placeCityEntry) HTML(c *gin.Context) (s template.HTML) {
 	return
 }
 
-func (g *Game) playCard(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) playCard(p *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	//	g.debugf("Play Card")
 	var index int
-	if index, err = g.validatePlayCard(c, cu); err != nil {
+	if index, err = g.validatePlayCard(p); err != nil {
 		tmpl = "indonesia/flash_notice"
 		return
 	}
@@ -150,18 +147,18 @@ func (g *Game) playCard(c *gin.Context, cu *user.User) (tmpl string, err error)
 
 	// Log placement
 	e := g.newPlaceCityEntryFor(cp, card)
-	restful.AddNoticef(c, string(e.HTML(c)))
+	g.emit(e)
 
 	tmpl = "indonesia/place_city_update"
 	return
 }
 
-func (g *Game) validatePlayCard(c *gin.Context, cu *user.User) (index int, err error) {
+func (g *Game) validatePlayCard(p *Player) (index int, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	index = g.SelectedCardIndex
-	if err = g.validatePlayerAction(cu); g.SelectedCardIndex < 0 || g.SelectedCardIndex > 1 {
+	if err = g.validatePlayerAction(p); err == nil && (index < 0 || index > 1 || index >= len(p.CityCards)) {
 		err = sn.NewVError("Recieved invalid card index.")
 	}
 	return
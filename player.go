@@ -17,6 +17,7 @@ import (
 
 func init() {
 	gob.RegisterName("Player", newPlayer())
+	registerStateType(newPlayer())
 }
 
 type Player struct {
@@ -30,6 +31,9 @@ type Player struct {
 	Technologies Technologies
 	Slots        Slots
 
+	// Sealed holds the sealed turn order bid of the player until bids are revealed.
+	Sealed *SealedBid `json:"-"`
+
 	cardsForCurrentEra        CityCards
 	canPlaceCity              int
 	newCityAreasForCurrentEra Areas
@@ -86,11 +90,18 @@ func (client *Client) determinePlaces(c *gin.Context, g *Game) ([]contest.Result
 	sort.Stable(Reverse{ByScore{players}})
 	g.setPlayers(players)
 
+	// Bot seats have no ratings, so only results between users are rated.
 	places := make([]contest.ResultsMap, 0)
 	for i, p1 := range g.Players() {
+		if g.IsBot(p1) {
+			continue
+		}
 		rmap := make(contest.ResultsMap, 0)
 		results := make([]*contest.Result, 0)
 		for j, p2 := range g.Players() {
+			if g.IsBot(p2) {
+				continue
+			}
 			r, err := client.Rating.For(c, p2.User(), g.Type)
 			if err != nil {
 				return nil, err
@@ -218,7 +229,11 @@ func (p *Player) clearActions() {
 
 func (p *Player) endOfTurnUpdate() {
 	p.PerformedAction = false
+	p.resetCache()
+}
 
+// resetCache clears values derived from the game state.
+func (p *Player) resetCache() {
 	// zero values not stored to datastore by stored in cache
 	p.cardsForCurrentEra = nil
 	p.canPlaceCity = 0
@@ -300,7 +315,7 @@ func (p *Player) CanPlaceCity() bool {
 
 func (p *Player) CanBid() bool {
 	return p != nil && p.Game().Phase == BidForTurnOrder &&
-		!p.PerformedAction
+		!p.PerformedAction && p.Sealed == nil
 }
 
 func (p *Player) CanAcquireCompany() bool {
@@ -323,7 +338,7 @@ func (p *Player) canPlaceInitialShip() bool {
 }
 
 func (p *Player) CanResearch() bool {
-	return p != nil && p.Game().Phase == Research && !p.PerformedAction
+	return p != nil && p.Game().Phase == Research && !p.PerformedAction && p.canAdvanceTechnology()
 }
 
 func (p *Player) CanExpandProduction() bool {
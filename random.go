@@ -0,0 +1,47 @@
+package indonesia
+
+import (
+	"math/rand"
+	"time"
+)
+
+// rand returns the random number generator of the game.
+// The generator is seeded by the persisted Seed and replays the persisted
+// number of draws, so the stream continues where it left off after the game
+// is loaded again.
+func (g *Game) rand() *rand.Rand {
+	if g.rng != nil {
+		return g.rng
+	}
+
+	if g.Seed == 0 {
+		g.Seed = time.Now().UnixNano()
+	}
+
+	src := &countingSource{Source: rand.NewSource(g.Seed)}
+	for i := int64(0); i < g.Draws; i++ {
+		src.Source.Int63()
+	}
+	src.seed, src.draws = &g.Seed, &g.Draws
+	g.rng = rand.New(src)
+	return g.rng
+}
+
+// countingSource counts the values drawn from the wrapped source, and records
+// the seed when the source is seeded again, so the persisted Seed and Draws
+// always reproduce the stream.
+type countingSource struct {
+	rand.Source
+	seed  *int64
+	draws *int64
+}
+
+func (s *countingSource) Int63() int64 {
+	*s.draws += 1
+	return s.Source.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	*s.seed, *s.draws = seed, 0
+	s.Source.Seed(seed)
+}
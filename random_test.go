@@ -0,0 +1,70 @@
+package indonesia
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// setup describes the outcome of the setup of a game: its turn order, the city
+// cards dealt to each player in turn order and the values drawn.
+type setup struct {
+	Order []int
+	Cards []string
+	Draws int64
+}
+
+func setupOf(t *testing.T, seed int64) setup {
+	t.Helper()
+	g, err := NewHeadless(seed, "a", "b", "c", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s setup
+	for _, pid := range g.OrderIDS {
+		s.Order = append(s.Order, pid)
+		cards := ""
+		for _, card := range g.PlayerByID(pid).CityCards {
+			cards += fmt.Sprintf("%v%d ", card.Era, card.Type)
+		}
+		s.Cards = append(s.Cards, cards)
+	}
+	s.Draws = g.Draws
+	return s
+}
+
+func TestSetupSeeded(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	want := setupOf(t, 7)
+	if want.Draws == 0 {
+		t.Fatal("setup drew no values")
+	}
+	if got := setupOf(t, 7); !reflect.DeepEqual(got, want) {
+		t.Errorf("setup with seed 7 gave %+v, then %+v", want, got)
+	}
+	if other := setupOf(t, 8); reflect.DeepEqual(other, want) {
+		t.Errorf("setups with seeds 7 and 8 both gave %+v", want)
+	}
+}
+
+// TestRandReseed checks that the stream of a game reseeded is reproduced once the game is loaded again.
+func TestRandReseed(t *testing.T) {
+	g := New(nil, 0)
+	g.Seed = 1
+	g.rand().Int63()
+	g.rand().Seed(2)
+	g.rand().Int63()
+	if g.Seed != 2 || g.Draws != 1 {
+		t.Fatalf("reseeded game has seed %d and %d draws, want 2 and 1", g.Seed, g.Draws)
+	}
+
+	loaded := New(nil, 0)
+	loaded.Seed, loaded.Draws = g.Seed, g.Draws
+	if got, want := loaded.rand().Int63(), g.rand().Int63(); got != want {
+		t.Errorf("loaded game drew %d, want %d", got, want)
+	}
+}
@@ -1,20 +1,17 @@
 package indonesia
 
 import (
-	"encoding/gob"
 	"html/template"
 	"strings"
 
-	"github.com/SlothNinja/game"
 	"github.com/SlothNinja/log"
 	"github.com/SlothNinja/restful"
 	"github.com/SlothNinja/sn"
-	"github.com/SlothNinja/user"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
-	gob.Register(new(researchEntry))
+	registerType(new(researchEntry))
 }
 
 type Technology int
@@ -54,23 +51,30 @@ func (p *Player) Expansions() int {
 	return p.Technologies[ExpansionsTech]
 }
 
-func (g *Game) startResearch(c *gin.Context) {
+func (g *Game) startResearch() {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	g.Phase = Research
 	g.beginningOfPhaseReset()
 	g.setCurrentPlayers(g.Players()[0])
+	if cp := g.CurrentPlayer(); !cp.CanResearch() {
+		g.autoPass(cp)
+		if np := g.researchNextPlayer(); np == nil {
+			g.startOperations()
+		} else {
+			g.setCurrentPlayers(np)
+		}
+	}
 }
 
-func (g *Game) conductResearch(c *gin.Context, cu *user.User) (tmpl string, err error) {
+func (g *Game) conductResearch(cp *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	var tech Technology
 
-	cp := g.CurrentPlayer()
-	switch tech, err = g.validateConductResearch(cu); {
+	switch tech, err = g.validateConductResearch(cp); {
 	case err != nil:
 	case tech == HullTech:
 		g.SubPhase = RSelectPlayer
@@ -84,24 +88,26 @@ func (g *Game) conductResearch(c *gin.Context, cu *user.User) (tmpl string, err
 
 		// Log
 		e := g.newResearchEntryFor(cp, nil, tech, cp.Technologies[tech])
-		restful.AddNoticef(c, string(e.HTML(c)))
+		g.emit(e)
 		tmpl = "indonesia/research_update"
 	}
 	return
 }
 
-func (g *Game) validateConductResearch(cu *user.User) (Technology, error) {
+func (g *Game) validateConductResearch(cp *Player) (Technology, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	cp, tech, err := g.CurrentPlayer(), g.SelectedTechnology, g.validatePlayerAction(cu)
+	tech, err := g.SelectedTechnology, g.validatePlayerAction(cp)
 	switch {
 	case err != nil:
-		return NoTech, nil
+		return NoTech, err
 	case tech < BidMultiplierTech || tech > HullTech:
 		return NoTech, sn.NewVError("Received invalid for researched technology.")
 	case tech != HullTech && cp.Technologies[tech] == 5:
 		return NoTech, sn.NewVError("Your %s is already at the maximum level.", tech)
+	case tech == HullTech && !g.canIncreaseHull():
+		return NoTech, sn.NewVError("The hull size of every player is already at the maximum level.")
 	default:
 		return tech, nil
 	}
@@ -142,42 +148,61 @@ func (e *researchEntry) HTML(c *gin.Context) (s template.HTML) {
 	}
 }
 
-func (g *Game) selectHullPlayer(c *gin.Context, cu *user.User) (tmpl string, act game.ActionType, err error) {
+func (g *Game) selectHullPlayer(cp *Player) (tmpl string, err error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
 	var p *Player
 
-	if p, err = g.validateSelectHullPlayer(c, cu); err != nil {
-		tmpl, act = "indonesia/flash_notice", game.None
+	if p, err = g.validateSelectHullPlayer(cp); err != nil {
+		tmpl = "indonesia/flash_notice"
 		return
 	}
 
-	cp := g.CurrentPlayer()
 	p.Technologies[HullTech] += 1
 	cp.PerformedAction = true
+	g.SubPhase = NoSubPhase
 
 	// Log
 	if cp.Equal(p) {
 		e := g.newResearchEntryFor(cp, nil, HullTech, p.Technologies[HullTech])
-		restful.AddNoticef(c, string(e.HTML(c)))
+		g.emit(e)
 	} else {
 		e := g.newResearchEntryFor(cp, p, HullTech, p.Technologies[HullTech])
-		restful.AddNoticef(c, string(e.HTML(c)))
+		g.emit(e)
 	}
-	tmpl, act = "indonesia/research_update", game.Cache
+	tmpl = "indonesia/research_update"
 	return
 }
 
-func (g *Game) validateSelectHullPlayer(c *gin.Context, cu *user.User) (*Player, error) {
+// canAdvanceTechnology reports whether p has a technology left to research.
+func (p *Player) canAdvanceTechnology() bool {
+	for t := BidMultiplierTech; t < HullTech; t++ {
+		if p.Technologies[t] < 5 {
+			return true
+		}
+	}
+	return p.Game().canIncreaseHull()
+}
+
+func (g *Game) canIncreaseHull() bool {
+	for _, p := range g.Players() {
+		if p.Technologies[HullTech] < 5 {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) validateSelectHullPlayer(cp *Player) (*Player, error) {
 	log.Debugf(msgEnter)
 	defer log.Debugf(msgExit)
 
-	if !g.IsCurrentPlayer(cu) {
-		return nil, sn.NewVError("Only the current player can perform an action.")
+	if err := g.validatePlayerAction(cp); err != nil {
+		return nil, err
 	}
 
-	p := g.PlayerBySID(c.PostForm("id"))
+	p := g.SelectedPlayer()
 	switch {
 	case p == nil:
 		return nil, sn.NewVError("Received invalid player.")
@@ -19,6 +19,18 @@ type Client struct {
 	Game   *game.Client
 	MLog   *mlog.Client
 	Rating *rating.Client
+
+	// Store holds the games of the client with their message logs, and the
+	// stats and contests saved as games finish.  Servers keep the Cloud
+	// Datastore default, since the Game and Rating clients query listings of
+	// games and ratings from Cloud Datastore.  Memory and file stores serve
+	// tests and the tools under cmd.
+	Store GameStore
+
+	// Turns holds the turns in progress.  It defaults to the cache of the
+	// client, local to the process; servers sharing games across instances
+	// set it to NewStoreTurnCache(Store).
+	Turns TurnCache
 }
 
 func NewClient(dClient *datastore.Client, uClient *user.Client, gClient *game.Client, mClient *mlog.Client,
@@ -29,6 +41,8 @@ func NewClient(dClient *datastore.Client, uClient *user.Client, gClient *game.Cl
 		Game:   gClient,
 		MLog:   mClient,
 		Rating: rClient,
+		Store:  NewDatastoreStore(dClient),
+		Turns:  NewMemoryTurnCache(cache),
 	}
 	return client.register(t)
 }
@@ -54,6 +68,42 @@ func (client *Client) addRoutes(prefix string) *Client {
 		client.show(prefix),
 	)
 
+	// Advice
+	g.GET("/show/:hid/advice",
+		client.fetch,
+		client.advise,
+	)
+
+	// Delivery Plans
+	g.GET("/show/:hid/deliveries",
+		client.fetch,
+		client.deliveryPlans,
+	)
+
+	// Merger Previews
+	g.GET("/show/:hid/mergers",
+		client.fetch,
+		client.mergerPreviews,
+	)
+
+	// History
+	g.GET("/show/:hid/at/:n",
+		client.fetch,
+		game.SetAdmin(false),
+		client.history(prefix, false),
+	)
+
+	g.GET("/show/:hid/at/:n/json",
+		client.fetch,
+		client.history(prefix, true),
+	)
+
+	// Record
+	g.GET("/record/:hid",
+		client.fetch,
+		client.exportRecord,
+	)
+
 	// Undo
 	g.POST("/undo/:hid",
 		client.fetch,
@@ -63,10 +113,15 @@ func (client *Client) addRoutes(prefix string) *Client {
 	// Finish
 	g.POST("/finish/:hid",
 		client.fetch,
-		client.User.StatsFetch,
+		client.statsFetch,
 		client.finish(prefix),
 	)
 
+	// Sealed Bid
+	g.POST("/sealed-bid/:hid",
+		client.sealedBid(prefix),
+	)
+
 	// Drop
 	g.POST("/drop/:hid",
 		client.fetch,
@@ -130,6 +185,13 @@ func (client *Client) addRoutes(prefix string) *Client {
 		client.update(prefix),
 	)
 
+	// Saved state as JSON
+	admin.GET("/:hid/state",
+		client.fetch,
+		game.SetAdmin(true),
+		client.stateJSON,
+	)
+
 	return client
 
 }
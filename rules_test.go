@@ -0,0 +1,224 @@
+package indonesia
+
+import (
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// headlessGame returns a headless game of three bots seeded with seed.
+func headlessGame(t *testing.T, seed int64) *Game {
+	t.Helper()
+	log.DefaultLevel = log.LvlNone
+	g, err := NewHeadless(seed, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// TestAcquireCompanyNoRoom checks that a deed whose province has no room left
+// to start its company can not be acquired, rather than leaving its owner to
+// place an initial product nowhere.
+func TestAcquireCompanyNoRoom(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Acquisitions && g.SubPhase == NoSubPhase })
+
+	deed := -1
+	for i, d := range g.AvailableDeeds {
+		if d.Goods != Shipping {
+			deed = i
+			break
+		}
+	}
+	if deed == -1 {
+		t.Fatal("no deed of a producing company is available")
+	}
+
+	// Fill the province as companies acquired earlier in the phase may have.
+	for _, a := range g.areasInProvince(g.AvailableDeeds[deed].Province) {
+		if !a.hasProducer() && !a.hasCity() {
+			a.City = &City{a: a, Size: 1}
+		}
+	}
+
+	pid := g.CPUserIndices[0]
+	for _, cmd := range LegalActions(g, pid) {
+		if cmd == (AcquireCompany{Deed: deed}) {
+			t.Errorf("acquiring deed %d is legal, want it refused", deed)
+		}
+	}
+	if _, err := g.Apply(pid, AcquireCompany{Deed: deed}); err == nil {
+		t.Errorf("acquired deed %d with no room to start it, want an error", deed)
+	}
+}
+
+// TestNewEraPlacesCities checks that a new era hands the turn to a player able
+// to place a city of the era, rather than leaving it with the last player of
+// the turn before, and that bidding for turn order follows once the cities are placed.
+func TestNewEraPlacesCities(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Operations })
+	if g.Era != EraA {
+		t.Fatalf("operations in era %v, want %v", g.Era, EraA)
+	}
+
+	// The last player of the turn has no city of the next era to place.
+	last := g.Players()[0]
+	var cards CityCards
+	for _, card := range last.CityCards {
+		if card.Era != EraB {
+			cards = append(cards, card)
+		}
+	}
+	last.CityCards = cards
+	g.setCurrentPlayers(last)
+
+	// With no deed left to acquire, the next turn begins a new era.
+	g.AvailableDeeds = nil
+	g.startNewEra()
+	if g.Era != EraB || g.Phase != NewEra {
+		t.Fatalf("game in era %v, phase %d, want era %v, phase %d", g.Era, g.Phase, EraB, NewEra)
+	}
+
+	cp := g.CurrentPlayer()
+	if cp == nil || cp.Equal(last) {
+		t.Fatalf("the new era begins with player %d, who has no city to place", last.ID())
+	}
+	var places int
+	for _, cmd := range LegalActions(g, cp.ID()) {
+		if _, ok := cmd.(PlaceCity); ok {
+			places++
+		}
+	}
+	if places == 0 {
+		t.Fatalf("player %d can not place a city of the new era", cp.ID())
+	}
+	playUntil(t, g, func(g *Game) bool { return g.Phase != NewEra })
+	if g.Phase != BidForTurnOrder {
+		t.Errorf("new era ended in phase %d, want %d", g.Phase, BidForTurnOrder)
+	}
+}
+
+// maxTechnologies raises every technology of p, and the hulls of every player, to the maximum.
+func maxTechnologies(g *Game, p *Player) {
+	for t := BidMultiplierTech; t < HullTech; t++ {
+		p.Technologies[t] = 5
+	}
+	for _, op := range g.Players() {
+		op.Technologies[HullTech] = 5
+	}
+}
+
+// TestResearchSkipsMaxedPlayer checks that the research phase passes by a
+// player with no technology left to research, rather than waiting for an
+// action they can not take.
+func TestResearchSkipsMaxedPlayer(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Research })
+
+	first := g.CurrentPlayer()
+	maxed := g.nextPlayer(first)
+	maxTechnologies(g, maxed)
+
+	playUntil(t, g, func(g *Game) bool { return !g.CurrentPlayer().Equal(first) })
+	if g.Phase == Research && g.CurrentPlayer().Equal(maxed) {
+		t.Errorf("player %d with every technology at the maximum is left to research", maxed.ID())
+	}
+}
+
+// TestResearchPassesFirstPlayer checks that the research phase passes by its
+// first player too when they have no technology left to research.
+func TestResearchPassesFirstPlayer(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Research })
+
+	first := g.Players()[0]
+	maxTechnologies(g, first)
+	g.startResearch()
+	if g.Phase == Research && g.CurrentPlayer().Equal(first) {
+		t.Fatalf("player %d with every technology at the maximum is left to research", first.ID())
+	}
+	playUntil(t, g, func(g *Game) bool { return g.Phase != Research })
+}
+
+// TestSelectHullPlayerEndsSubPhase checks that selecting the player whose hull
+// grows ends the subphase, so the next researcher conducts research rather
+// than selecting a hull player of their own.
+func TestSelectHullPlayerEndsSubPhase(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Research })
+
+	cp := g.CurrentPlayer()
+	for _, cmd := range []Command{ConductResearch{Technology: HullTech}, SelectHullPlayer{PlayerID: cp.ID()}} {
+		if _, err := g.Apply(cp.ID(), cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.SubPhase != NoSubPhase {
+		t.Errorf("research in subphase %d after the hull player was selected, want %d", g.SubPhase, NoSubPhase)
+	}
+
+	if _, err := g.Apply(cp.ID(), FinishTurn{}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Phase != Research {
+		t.Fatalf("game in phase %d, want a second researcher", g.Phase)
+	}
+	for _, cmd := range LegalActions(g, g.CurrentPlayer().ID()) {
+		if _, ok := cmd.(SelectHullPlayer); ok {
+			t.Errorf("player %d may select a hull player without researching", g.CurrentPlayer().ID())
+			break
+		}
+	}
+}
+
+// TestBidInDebt checks that a player left in debt by expanding may still bid
+// nothing for turn order, and so take their turn.
+func TestBidInDebt(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == BidForTurnOrder })
+
+	p := g.CurrentPlayer()
+	p.Rupiah = -5
+	var bids []Command
+	for _, cmd := range LegalActions(g, p.ID()) {
+		if _, ok := cmd.(TurnOrderBid); ok {
+			bids = append(bids, cmd)
+		}
+	}
+	if len(bids) != 1 || bids[0] != (TurnOrderBid{Bid: 0}) {
+		t.Errorf("player in debt may bid %v, want only nothing", bids)
+	}
+	playUntil(t, g, func(g *Game) bool { return g.Phase != BidForTurnOrder })
+}
+
+// TestCityGrowthClearsActions checks that city growth clears the actions of
+// the Operations phase, so a player passed by at its end may still choose
+// the cities to grow.
+func TestCityGrowthClearsActions(t *testing.T) {
+	g := headlessGame(t, 1)
+	playUntil(t, g, func(g *Game) bool { return g.Phase == Operations })
+
+	// Every city may grow, but the stones of one size 2 city are left.
+	produced := g.ProducedGoods()
+	for _, c := range g.Cities() {
+		for i, b := range produced {
+			if b {
+				c.Delivered[i] = c.Size
+			}
+		}
+	}
+	g.CityStones[Size2], g.CityStones[Size3] = 1, 0
+	if n := len(g.CityGrowthMap()[1]); n < 2 {
+		t.Fatalf("%d size 1 cities may grow, want at least 2", n)
+	}
+
+	first := g.Players()[0]
+	first.PerformedAction = true
+	g.startCityGrowth()
+	if g.Phase != CityGrowth || !g.CurrentPlayer().Equal(first) {
+		t.Fatalf("game in phase %d, want player %d to choose the cities to grow", g.Phase, first.ID())
+	}
+	playUntil(t, g, func(g *Game) bool { return g.Phase != CityGrowth })
+}
@@ -0,0 +1,276 @@
+package indonesia
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/restful"
+	"github.com/SlothNinja/sn"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	registerType(new(sealedBidEntry))
+	registerType(new(revealedBidEntry))
+}
+
+// SealedBid is a turn order bid kept from the other players until every player has bid.
+// Commitment, published when the bid is placed, is the hash of the bid and Salt,
+// so once Salt is revealed anyone can check the bid was not changed.
+type SealedBid struct {
+	Bid        int
+	Salt       string
+	Commitment string
+}
+
+// Commitment returns the commitment of the player having id pid to bid with salt.
+func Commitment(pid, bid int, salt string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%s", pid, bid, salt)))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSalt() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// startSealedBids lets every player bid for turn order at once.
+func (g *Game) startSealedBids() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	for _, p := range g.Players() {
+		p.Sealed = nil
+	}
+	g.setCurrentPlayers(g.Players()...)
+}
+
+func (g *Game) sealTurnOrderBid(p *Player, bid int) (string, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	salt, err := newSalt()
+	if err != nil {
+		return "indonesia/flash_notice", err
+	}
+
+	p.Sealed = &SealedBid{Bid: bid, Salt: salt, Commitment: Commitment(p.ID(), bid, salt)}
+	p.PerformedAction = true
+
+	e := g.newSealedBidEntryFor(p)
+	g.emit(e)
+	return "indonesia/turn_order_bid_update", nil
+}
+
+// sealedBidsFinishTurn removes cp from the players yet to bid, and sets the turn
+// order once all have bid.
+func (g *Game) sealedBidsFinishTurn(cp *Player) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	cp.endOfTurnUpdate()
+	var waiting []*Player
+	for _, p := range g.Players() {
+		if g.isCurrentPlayer(p) && !p.Equal(cp) {
+			waiting = append(waiting, p)
+		}
+	}
+
+	if len(waiting) == 0 {
+		g.setTurnOrder()
+		return
+	}
+	g.setCurrentPlayers(waiting...)
+}
+
+// revealSealedBids pays the sealed bid of each player into its bank.
+func (g *Game) revealSealedBids() {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	for _, p := range g.Players() {
+		sealed := p.Sealed
+		if sealed == nil {
+			continue
+		}
+		p.Sealed = nil
+		p.Bid = sealed.Bid
+		p.Bank += p.Bid
+		p.Rupiah -= p.Bid
+		g.newRevealedBidEntryFor(p, sealed)
+	}
+}
+
+// sealedBidsPending reports whether a player holds a sealed bid yet to be revealed.
+func (g *Game) sealedBidsPending() bool {
+	for _, p := range g.Players() {
+		if p.Sealed != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// openJournal returns the journal without the sealed bids yet to be revealed.
+func (g *Game) openJournal() Journal {
+	if !g.sealedBidsPending() {
+		return g.Journal
+	}
+
+	var journal Journal
+	for _, r := range g.Journal {
+		if _, ok := r.Command.(TurnOrderBid); ok && g.PlayerByID(r.PlayerID).Sealed != nil {
+			continue
+		}
+		journal = append(journal, r)
+	}
+	return journal
+}
+
+// forgetSealedBids drops the sealed bids of the players other than the player
+// having id pid, who are left to bid again.  Bots searching a clone of the game
+// so can not learn the bids of their rivals.
+func (g *Game) forgetSealedBids(pid int) {
+	if !g.SealedBids || g.Phase != BidForTurnOrder {
+		return
+	}
+
+	var ps []*Player
+	if p := g.PlayerByID(pid); g.isCurrentPlayer(p) {
+		ps = append(ps, p)
+	}
+	for _, p := range g.Players() {
+		switch {
+		case p.ID() == pid:
+		case p.Sealed != nil:
+			p.Sealed = nil
+			p.PerformedAction = false
+			ps = append(ps, p)
+		case g.isCurrentPlayer(p):
+			ps = append(ps, p)
+		}
+	}
+	g.setCurrentPlayers(ps...)
+}
+
+type sealedBidEntry struct {
+	*Entry
+	Commitment string
+}
+
+func (g *Game) newSealedBidEntryFor(p *Player) (e *sealedBidEntry) {
+	e = &sealedBidEntry{
+		Entry:      g.newEntryFor(p),
+		Commitment: p.Sealed.Commitment,
+	}
+	p.Log = append(p.Log, e)
+	g.Log = append(g.Log, e)
+	return
+}
+
+func (e *sealedBidEntry) HTML(c *gin.Context) template.HTML {
+	g := gameFrom(c)
+	return restful.HTML("<div>%s placed a sealed bid (commitment %s).</div>",
+		g.NameByPID(e.PlayerID), e.Commitment)
+}
+
+type revealedBidEntry struct {
+	*Entry
+	Bid           int
+	BidMultiplier int
+	Salt          string
+	Commitment    string
+}
+
+func (g *Game) newRevealedBidEntryFor(p *Player, sealed *SealedBid) (e *revealedBidEntry) {
+	e = &revealedBidEntry{
+		Entry:         g.newEntryFor(p),
+		Bid:           sealed.Bid,
+		BidMultiplier: p.Multiplier(),
+		Salt:          sealed.Salt,
+		Commitment:    sealed.Commitment,
+	}
+	p.Log = append(p.Log, e)
+	g.Log = append(g.Log, e)
+	return
+}
+
+func (e *revealedBidEntry) HTML(c *gin.Context) template.HTML {
+	g := gameFrom(c)
+	verified := "does not match"
+	if Commitment(e.PlayerID, e.Bid, e.Salt) == e.Commitment {
+		verified = "matches"
+	}
+	return restful.HTML("<div>%s bid %d &times; %d for a total bid of %d (salt %s %s the commitment).</div>",
+		g.NameByPID(e.PlayerID), e.Bid, e.BidMultiplier, e.Bid*e.BidMultiplier, e.Salt, verified)
+}
+
+// sealedBid places the sealed turn order bid of the current user and finishes its turn
+// in one step.  The bid is applied to the stored game rather than to the cached turn
+// of the user, so players may bid at the same time without overwriting each other.
+// Once the bid is saved, the bots play on in a step of their own, so a retried bid
+// never waits on the bots.
+func (client *Client) sealedBid(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client.Log.Debugf(msgEnter)
+		defer client.Log.Debugf(msgExit)
+
+		defer c.Redirect(http.StatusSeeOther, showPath(prefix, c.Param(hParam)))
+
+		cu, err := client.User.Current(c)
+		if err != nil {
+			client.Log.Errorf(err.Error())
+			return
+		}
+
+		id, err := getID(c)
+		if err != nil {
+			client.Log.Errorf(err.Error())
+			return
+		}
+
+		bid, err := strconv.Atoi(c.PostForm("bid"))
+		if err != nil {
+			restful.AddErrorf(c, "Received invalid bid.")
+			return
+		}
+
+		_, err = client.retrySave(c, id, cu, func(g *Game) (Events, bool, error) {
+			es, err := g.placeSealedBid(g.playerIDFor(cu), bid)
+			return es, true, err
+		})
+		if err != nil {
+			restful.AddErrorf(c, "%v", err)
+			return
+		}
+
+		client.playStoredBots(c, id, cu)
+	}
+}
+
+// placeSealedBid bids for the player having id pid and finishes its turn.
+func (g *Game) placeSealedBid(pid, bid int) (Events, error) {
+	if !g.SealedBids {
+		return nil, sn.NewVError("This game does not use sealed bids.")
+	}
+
+	es, err := g.Apply(pid, TurnOrderBid{Bid: bid})
+	if err != nil {
+		return nil, err
+	}
+
+	fes, err := g.Apply(pid, FinishTurn{})
+	if err != nil {
+		return nil, err
+	}
+	return append(es, fes...), nil
+}
@@ -31,55 +31,57 @@ func (g *Game) selectArea(c *gin.Context, cu *user.User) (string, game.ActionTyp
 	case "admin-company":
 		return "indonesia/admin/company_dialog", game.Cache, nil
 	default:
-		switch {
-		case cp.CanSelectCard():
-			tmpl, err := g.playCard(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanPlaceCity():
-			tmpl, err := g.placeCity(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanAcquireCompany():
-			tmpl, err := g.acquireCompany(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanResearch():
-			tmpl, err := g.conductResearch(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanSelectCompanyToOperate():
-			tmpl, err := g.selectCompany(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanSelectGood():
-			tmpl, err := g.selectGood(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanSelectShip():
-			tmpl, err := g.selectShip(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanSelectCityOrShip():
-			tmpl, err := g.selectCityOrShip(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanExpandProduction():
-			tmpl, err := g.expandProduction(c, cu)
-			return tmpl, game.Cache, err
-		case cp.canExpandShipping():
-			tmpl, err := g.expandShipping(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanAnnounceMerger():
-			tmpl, err := g.selectCompany1(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanAnnounceSecondCompany():
-			tmpl, err := g.selectCompany2(c, cu)
-			return tmpl, game.Cache, err
-		case cp.canPlaceInitialProduct():
-			tmpl, err := g.placeInitialProduct(c, cu)
-			return tmpl, game.Cache, err
-		case cp.canPlaceInitialShip():
-			tmpl, err := g.placeInitialShip(c, cu)
-			return tmpl, game.Cache, err
-		case cp.CanCreateSiapFaji():
-			tmpl, err := g.removeRiceSpice(c, cu)
-			return tmpl, game.Cache, err
+		cmd, err := g.selectAreaCommand(cp)
+		if err != nil {
+			return "indonesia/flash_notice", game.None, err
+		}
+		return g.update(c, cu, cmd)
+	}
+}
+
+// selectAreaCommand converts the selection recorded by validateSelectArea
+// into the command the current player is able to perform.
+func (g *Game) selectAreaCommand(cp *Player) (Command, error) {
+	switch {
+	case cp.CanSelectCard():
+		return PlayCard{Card: g.SelectedCardIndex}, nil
+	case cp.CanPlaceCity():
+		return PlaceCity{Area: g.SelectedAreaID}, nil
+	case cp.CanAcquireCompany():
+		return AcquireCompany{Deed: g.SelectedDeedIndex}, nil
+	case cp.CanResearch():
+		return ConductResearch{Technology: g.SelectedTechnology}, nil
+	case cp.CanSelectCompanyToOperate():
+		return OperateCompany{Slot: g.SelectedSlot}, nil
+	case cp.CanSelectGood():
+		return SelectGood{Area: g.SelectedAreaID}, nil
+	case cp.CanSelectShip():
+		return SelectShip{Area: g.SelectedArea2ID, Shipper: g.SelectedShipperIndex}, nil
+	case cp.CanSelectCityOrShip():
+		switch a := g.SelectedArea2(); {
+		case a == nil:
+			return nil, sn.NewVError("You must select an area having a city or boat.")
+		case a.IsSea():
+			return SelectShip{Area: g.SelectedArea2ID, Shipper: g.SelectedShipperIndex}, nil
 		default:
-			return "indonesia/flash_notice", game.None, sn.NewVError("Can't find action for selection.")
+			return SelectCity{Area: g.SelectedArea2ID}, nil
 		}
+	case cp.CanExpandProduction():
+		return ExpandProduction{Area: g.SelectedAreaID}, nil
+	case cp.canExpandShipping():
+		return ExpandShipping{Area: g.SelectedAreaID}, nil
+	case cp.CanAnnounceMerger():
+		return AnnounceMerger{OwnerID: g.SelectedPlayerID, Slot: g.SelectedSlot}, nil
+	case cp.CanAnnounceSecondCompany():
+		return AnnounceMergerPartner{OwnerID: g.SelectedPlayerID, Slot: g.SelectedSlot}, nil
+	case cp.canPlaceInitialProduct():
+		return PlaceInitialProduct{Area: g.SelectedAreaID}, nil
+	case cp.canPlaceInitialShip():
+		return PlaceInitialShip{Area: g.SelectedAreaID}, nil
+	case cp.CanCreateSiapFaji():
+		return RemoveRiceSpice{Area: g.SelectedAreaID}, nil
+	default:
+		return nil, sn.NewVError("Can't find action for selection.")
 	}
 }
 
@@ -0,0 +1,196 @@
+package indonesia
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+)
+
+// maxRemovalSets caps the removal sets weighed by bots and the move advisor.
+const maxRemovalSets = 10
+
+// RemovalSet is a choice of the rice and spice areas removed while forming a
+// Siap Faji company, with the company left by the removal.  Deliveries is the
+// most goods the company could deliver with the ships now on the board, and
+// Zones the number of its zones.
+type RemovalSet struct {
+	Areas      AreaIDS `json:"areas"`
+	Deliveries int     `json:"deliveries"`
+	Zones      int     `json:"zones"`
+}
+
+// removalSets holds the removal sets found for the company having areas.
+type removalSets struct {
+	areas areaSet
+	sets  []RemovalSet
+}
+
+// RemovalSets returns the sets of areas whose removal completes the Siap Faji
+// company, most deliveries first and then fewest zones.  The areas bordering
+// competing companies are removed when the merger is resolved, and removing
+// more areas can not create new borders, so any set of GoodsToRemove areas of
+// the company will do.  Sets leaving zones of the same shape are alike, so only
+// one set of each shape is returned.  The sets are found once for each state of
+// the company.
+func (m *SiapFajiMerger) RemovalSets() []RemovalSet {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if m == nil || m.Company() == nil {
+		return nil
+	}
+
+	areas := m.Company().Zones.set()
+	if m.removals == nil || m.removals.areas != areas {
+		m.removals = &removalSets{areas: areas, sets: newRemovalSearch(m).run()}
+	}
+	return m.removals.sets
+}
+
+// removalSetOf returns the removal set removing the areas of ids.
+func (m *SiapFajiMerger) removalSetOf(ids AreaIDS) RemovalSet {
+	zones := m.zonesLeft(ids)
+	return RemovalSet{
+		Areas:      setOf(ids...).ids(),
+		Deliveries: m.companyWith(zones).MaxDeliveries(),
+		Zones:      len(zones),
+	}
+}
+
+// removalSearch enumerates every set of count areas of the company.  The
+// deliveries of the company left by a set depend only on the shape of its
+// zones, so each shape is solved once and only the first set found leaving
+// it is kept.
+type removalSearch struct {
+	m          *SiapFajiMerger
+	areas      AreaIDS
+	count      int
+	sets       []RemovalSet
+	deliveries map[string]int
+}
+
+func newRemovalSearch(m *SiapFajiMerger) *removalSearch {
+	com := m.Company()
+	areas := com.Areas().IDS()
+
+	// Areas bordering the fewest seas are tried for removal first, so the set
+	// kept for each shape removes the areas least likely to carry deliveries.
+	seas := make(map[AreaID]int, len(areas))
+	for _, id := range areas {
+		seas[id] = len(m.g.neighbors(id).intersect(seaSet).ids())
+	}
+	sort.SliceStable(areas, func(i, j int) bool { return seas[areas[i]] < seas[areas[j]] })
+
+	return &removalSearch{
+		m:          m,
+		areas:      areas,
+		count:      m.GoodsToRemove(),
+		deliveries: make(map[string]int),
+	}
+}
+
+func (s *removalSearch) run() []RemovalSet {
+	if s.count <= 0 || s.count > len(s.areas) {
+		return nil
+	}
+	s.search(0, nil)
+	sort.SliceStable(s.sets, func(i, j int) bool {
+		if s.sets[i].Deliveries != s.sets[j].Deliveries {
+			return s.sets[i].Deliveries > s.sets[j].Deliveries
+		}
+		return s.sets[i].Zones < s.sets[j].Zones
+	})
+	return s.sets
+}
+
+func (s *removalSearch) search(i int, removed AreaIDS) {
+	if len(removed) == s.count {
+		zones := s.m.zonesLeft(removed)
+		key := s.shapeOf(zones)
+		if _, seen := s.deliveries[key]; seen {
+			return
+		}
+		deliveries := s.m.companyWith(zones).MaxDeliveries()
+		s.deliveries[key] = deliveries
+		s.sets = append(s.sets, RemovalSet{Areas: setOf(removed...).ids(), Deliveries: deliveries, Zones: len(zones)})
+		return
+	}
+	if len(s.areas)-i < s.count-len(removed) {
+		return
+	}
+
+	s.search(i+1, append(removed, s.areas[i]))
+	s.search(i+1, removed)
+}
+
+// shapeOf returns the shape of zones.  The deliveries of a zone depend only on
+// its size and the seas it borders, so zones of the same shape deliver alike.
+func (s *removalSearch) shapeOf(zones Zones) string {
+	keys := make([]string, len(zones))
+	for i, zone := range zones {
+		keys[i] = fmt.Sprint(len(zone.AreaIDS), s.m.g.neighborhood(zone.set()).intersect(seaSet))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
+
+// zonesLeft returns the zones of the company left by removing the areas of ids.
+// The areas left form zones as they will once all hold Siap Faji.
+func (m *SiapFajiMerger) zonesLeft(ids AreaIDS) Zones {
+	var zones Zones
+	for _, part := range m.g.components(m.Company().Zones.set().minus(setOf(ids...))) {
+		zones = append(zones, newZone(m.g, part.ids()))
+	}
+	return zones
+}
+
+func (m *SiapFajiMerger) companyWith(zones Zones) *Company {
+	com := m.Company()
+	return &Company{g: m.g, OwnerID: com.OwnerID, Slot: com.Slot, Deeds: com.Deeds, Zones: zones}
+}
+
+// removeRiceSpiceSet removes the rice and spice in each area of ids, completing the Siap Faji company.
+func (g *Game) removeRiceSpiceSet(cp *Player, ids AreaIDS) (string, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	if err := g.validateRemoveRiceSpiceSet(cp, ids); err != nil {
+		return "indonesia/flash_notice", err
+	}
+
+	var tmpl string
+	for _, id := range ids {
+		g.SelectedAreaID = id
+		var err error
+		if tmpl, err = g.removeRiceSpice(cp); err != nil {
+			return tmpl, err
+		}
+	}
+	return tmpl, nil
+}
+
+func (g *Game) validateRemoveRiceSpiceSet(cp *Player, ids AreaIDS) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	m := g.SiapFajiMerger
+	switch {
+	case m == nil:
+		return sn.NewVError("No Siap Faji Merger defined.")
+	case len(ids) != m.GoodsToRemove():
+		return sn.NewVError("You must remove %d rice/spice.", m.GoodsToRemove())
+	case len(setOf(ids...).ids()) != len(ids):
+		return sn.NewVError("You can not remove the goods of an area twice.")
+	}
+
+	for _, id := range ids {
+		g.SelectedAreaID = id
+		if _, _, err := g.validateRemoveRiceSpice(cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
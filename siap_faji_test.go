@@ -0,0 +1,86 @@
+package indonesia
+
+import (
+	"testing"
+
+	"github.com/SlothNinja/log"
+)
+
+// siapFajiCreation plays a bot game having seed until a Siap Faji company is
+// to be completed by removing goods.
+func siapFajiCreation(t *testing.T, seed int64) *Game {
+	t.Helper()
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(seed, "a", "b", "c", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	playUntil(t, g, func(g *Game) bool {
+		return g.SubPhase == MSiapFajiCreation && g.SiapFajiMerger.GoodsToRemove() > 1
+	})
+	return g
+}
+
+// removals calls f with each set of count areas of ids.
+func removals(ids AreaIDS, count int, removed AreaIDS, f func(AreaIDS)) {
+	switch {
+	case len(removed) == count:
+		f(append(AreaIDS(nil), removed...))
+	case len(ids) >= count-len(removed):
+		removals(ids[1:], count, append(removed, ids[0]), f)
+		removals(ids[1:], count, removed, f)
+	}
+}
+
+// TestRemovalSets checks that RemovalSets holds one set leaving each shape of
+// zones that any removal leaves, best first, and that a set left out as alike
+// another may still be removed.
+func TestRemovalSets(t *testing.T) {
+	g := siapFajiCreation(t, 5)
+	m := g.SiapFajiMerger
+	sets := m.RemovalSets()
+	if len(sets) == 0 {
+		t.Fatal("found no removal set")
+	}
+	for i := 1; i < len(sets); i++ {
+		if sets[i].Deliveries > sets[i-1].Deliveries {
+			t.Errorf("set %v delivers more than the set %v ranked before it", sets[i], sets[i-1])
+		}
+	}
+
+	search := newRemovalSearch(m)
+	shapes := make(map[string]bool)
+	for _, set := range sets {
+		key := search.shapeOf(m.zonesLeft(set.Areas))
+		if shapes[key] {
+			t.Errorf("set %v leaves the shape of another set", set)
+		}
+		shapes[key] = true
+	}
+
+	var other AreaIDS
+	removals(m.Company().Areas().IDS(), m.GoodsToRemove(), nil, func(ids AreaIDS) {
+		if !shapes[search.shapeOf(m.zonesLeft(ids))] {
+			t.Errorf("no set leaves the shape left by removing %v", ids)
+		}
+		if set := m.removalSetOf(ids); set.Deliveries > sets[0].Deliveries {
+			t.Errorf("removing %v delivers %d, more than the best set %v", ids, set.Deliveries, sets[0])
+		}
+		listed := false
+		for _, set := range sets {
+			listed = listed || set.Areas.same(ids)
+		}
+		if !listed {
+			other = ids
+		}
+	})
+	if other == nil {
+		t.Fatal("every removal is listed")
+	}
+
+	pid := g.CPUserIndices[0]
+	if _, err := g.Apply(pid, RemoveRiceSpiceSet{Areas: other}); err != nil {
+		t.Errorf("removing %v: %v", other, err)
+	}
+}
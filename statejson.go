@@ -0,0 +1,570 @@
+package indonesia
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/SlothNinja/codec"
+)
+
+// Encodings of the saved state of a game.  Games use gob unless they select JSON.
+const (
+	gobEncoding  = "gob"
+	jsonEncoding = "json"
+)
+
+// JSON state encoding
+//
+// The JSON encoding holds what gob holds: the exported fields of each struct,
+// under their Go names, whatever their json tags.  Fields with zero values,
+// including empty slices, maps and strings, are left out.  Each interface
+// value, such as a log entry or a journaled command, is an object
+// {"type": name, "value": value} naming its registered type.  Times are
+// RFC 3339 strings, byte slices base64 strings, and maps objects when their
+// keys are strings or integers, or else lists of {"key": key, "value": value}.
+// Unknown fields are ignored when decoding, as gob ignores them.
+
+var (
+	stateTypes     = make(map[string]reflect.Type)
+	stateTypeNames = make(map[reflect.Type]string)
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// registerType registers the type of v, held by interface values of the state,
+// with gob and the JSON state encoding.
+func registerType(v interface{}) {
+	gob.Register(v)
+	registerStateType(v)
+}
+
+// registerStateType registers the type of v with the JSON state encoding under its name.
+func registerStateType(v interface{}) {
+	t := reflect.TypeOf(v)
+	name := t.Name()
+	if t.Kind() == reflect.Ptr {
+		name = t.Elem().Name()
+	}
+	if _, ok := stateTypes[name]; ok {
+		panic(fmt.Sprintf("state type %s registered twice", name))
+	}
+	stateTypes[name], stateTypeNames[t] = t, name
+}
+
+// encodeStateJSON returns the JSON encoding of s.
+func encodeStateJSON(s *State) ([]byte, error) {
+	return encodeJSONValue(s)
+}
+
+// decodeStateJSON returns the state encoded in data by encodeStateJSON.
+func decodeStateJSON(data []byte) (*State, error) {
+	s := newState()
+	if err := decodeJSONValue(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// encodeJSONValue returns the JSON encoding of v, a value holding state, such as a page of records.
+func encodeJSONValue(v interface{}) ([]byte, error) {
+	e := new(stateEncoder)
+	if err := e.value(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// decodeJSONValue decodes data, encoded by encodeJSONValue, into the value v points to.
+func decodeJSONValue(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var tree interface{}
+	if err := d.Decode(&tree); err != nil {
+		return err
+	}
+	return decodeStateValue(tree, reflect.ValueOf(v).Elem())
+}
+
+// isJSONState reports whether data holds a state encoded in JSON.  A gob stream
+// opens with the definition of a type, whose negative id is not valid JSON.
+func isJSONState(data []byte) bool {
+	return len(data) > 0 && data[0] == '{' && json.Valid(data)
+}
+
+type stateEncoder struct {
+	bytes.Buffer
+}
+
+func (e *stateEncoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		elem := v.Elem()
+		name, ok := stateTypeNames[elem.Type()]
+		if !ok {
+			return fmt.Errorf("type %v is not registered", elem.Type())
+		}
+		e.WriteString(`{"type":`)
+		e.string(name)
+		e.WriteString(`,"value":`)
+		if err := e.value(elem); err != nil {
+			return err
+		}
+		e.WriteByte('}')
+	case reflect.Struct:
+		return e.structValue(v)
+	case reflect.Map:
+		return e.mapValue(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.string(base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		return e.list(v)
+	case reflect.Array:
+		return e.list(v)
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Bool:
+		e.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	default:
+		return fmt.Errorf("can not encode %v", v.Type())
+	}
+	return nil
+}
+
+func (e *stateEncoder) structValue(v reflect.Value) error {
+	if v.Type() == timeType {
+		b, err := v.Interface().(time.Time).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		e.Write(b)
+		return nil
+	}
+
+	e.WriteByte('{')
+	first := true
+	for i := 0; i < v.NumField(); i++ {
+		f, fv := v.Type().Field(i), v.Field(i)
+		if !stateField(f) || emptyStateValue(fv) {
+			continue
+		}
+		if !first {
+			e.WriteByte(',')
+		}
+		first = false
+		e.string(f.Name)
+		e.WriteByte(':')
+		if err := e.value(fv); err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+func (e *stateEncoder) mapValue(v reflect.Value) error {
+	if v.IsNil() {
+		e.WriteString("null")
+		return nil
+	}
+
+	keys := v.MapKeys()
+	if byName := nameKeys(v.Type().Key()); byName {
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+		e.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.string(keyName(k))
+			e.WriteByte(':')
+			if err := e.value(v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		e.WriteByte('}')
+		return nil
+	}
+
+	pairs := make([][]byte, len(keys))
+	for i, k := range keys {
+		pe := new(stateEncoder)
+		pe.WriteString(`{"key":`)
+		if err := pe.value(k); err != nil {
+			return err
+		}
+		pe.WriteString(`,"value":`)
+		if err := pe.value(v.MapIndex(k)); err != nil {
+			return err
+		}
+		pe.WriteByte('}')
+		pairs[i] = pe.Bytes()
+	}
+	sort.Slice(pairs, func(i, j int) bool { return bytes.Compare(pairs[i], pairs[j]) < 0 })
+	e.WriteByte('[')
+	e.Write(bytes.Join(pairs, []byte{','}))
+	e.WriteByte(']')
+	return nil
+}
+
+func (e *stateEncoder) list(v reflect.Value) error {
+	e.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		if err := e.value(v.Index(i)); err != nil {
+			return fmt.Errorf("%d: %v", i, err)
+		}
+	}
+	e.WriteByte(']')
+	return nil
+}
+
+func (e *stateEncoder) string(s string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	e.Write(bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}))
+}
+
+// stateField reports whether gob encodes the field f.
+func stateField(f reflect.StructField) bool {
+	if f.PkgPath != "" {
+		return false
+	}
+	switch f.Type.Kind() {
+	case reflect.Chan, reflect.Func:
+		return false
+	default:
+		return true
+	}
+}
+
+// emptyStateValue reports whether v is left out as gob leaves it out.
+func emptyStateValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// nameKeys reports whether maps keyed by t encode as JSON objects.
+func nameKeys(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func keyName(k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	default:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+}
+
+func keyLess(k1, k2 reflect.Value) bool {
+	switch k1.Kind() {
+	case reflect.String:
+		return k1.String() < k2.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return k1.Int() < k2.Int()
+	default:
+		return k1.Uint() < k2.Uint()
+	}
+}
+
+// decodeStateValue sets v to the value of the decoded JSON tree.
+func decodeStateValue(tree interface{}, v reflect.Value) error {
+	if tree == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := decodeStateValue(tree, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Interface:
+		obj, ok := tree.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v must be an object naming its type", v.Type())
+		}
+		name, _ := obj["type"].(string)
+		t, ok := stateTypes[name]
+		if !ok {
+			return fmt.Errorf("type %q is not registered", name)
+		}
+		if !t.AssignableTo(v.Type()) {
+			return fmt.Errorf("type %s is not a %v", name, v.Type())
+		}
+		elem := reflect.New(t).Elem()
+		if err := decodeStateValue(obj["value"], elem); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		v.Set(elem)
+	case reflect.Struct:
+		return decodeStateStruct(tree, v)
+	case reflect.Map:
+		return decodeStateMap(tree, v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			s, ok := tree.(string)
+			if !ok {
+				return fmt.Errorf("%v must be a base64 string", v.Type())
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		list, ok := tree.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v must be a list", v.Type())
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(list), len(list)))
+		return decodeStateList(list, v)
+	case reflect.Array:
+		list, ok := tree.([]interface{})
+		if !ok || len(list) != v.Len() {
+			return fmt.Errorf("%v must be a list of %d", v.Type(), v.Len())
+		}
+		return decodeStateList(list, v)
+	case reflect.String:
+		s, ok := tree.(string)
+		if !ok {
+			return fmt.Errorf("%v must be a string", v.Type())
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, ok := tree.(bool)
+		if !ok {
+			return fmt.Errorf("%v must be true or false", v.Type())
+		}
+		v.SetBool(b)
+	default:
+		n, ok := tree.(json.Number)
+		if !ok {
+			return fmt.Errorf("%v must be a number", v.Type())
+		}
+		return setStateNumber(n.String(), v)
+	}
+	return nil
+}
+
+func decodeStateStruct(tree interface{}, v reflect.Value) error {
+	if v.Type() == timeType {
+		s, ok := tree.(string)
+		if !ok {
+			return fmt.Errorf("time must be a string")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	obj, ok := tree.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%v must be an object", v.Type())
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		sub, ok := obj[f.Name]
+		if !ok || !stateField(f) {
+			continue
+		}
+		if err := decodeStateValue(sub, v.Field(i)); err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeStateMap(tree interface{}, v reflect.Value) error {
+	t := v.Type()
+	m := reflect.MakeMap(t)
+	switch entries := tree.(type) {
+	case map[string]interface{}:
+		if !nameKeys(t.Key()) {
+			return fmt.Errorf("%v must be a list of keys and values", t)
+		}
+		for name, sub := range entries {
+			k := reflect.New(t.Key()).Elem()
+			var err error
+			if k.Kind() == reflect.String {
+				k.SetString(name)
+			} else {
+				err = setStateNumber(name, k)
+			}
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := decodeStateValue(sub, elem); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			m.SetMapIndex(k, elem)
+		}
+	case []interface{}:
+		for i, entry := range entries {
+			pair, ok := entry.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%d: must be an object of key and value", i)
+			}
+			k, elem := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			if err := decodeStateValue(pair["key"], k); err != nil {
+				return fmt.Errorf("%d: %v", i, err)
+			}
+			if err := decodeStateValue(pair["value"], elem); err != nil {
+				return fmt.Errorf("%d: %v", i, err)
+			}
+			m.SetMapIndex(k, elem)
+		}
+	default:
+		return fmt.Errorf("%v must be an object", t)
+	}
+	v.Set(m)
+	return nil
+}
+
+func decodeStateList(list []interface{}, v reflect.Value) error {
+	for i, sub := range list {
+		if err := decodeStateValue(sub, v.Index(i)); err != nil {
+			return fmt.Errorf("%d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func setStateNumber(s string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("can not decode %v", v.Type())
+	}
+	return nil
+}
+
+// verifyStateJSON checks that the state of g survives the JSON encoding, the
+// gob encoding, and the JSON encoding followed by the gob encoding, by
+// comparing the JSON encodings of the states they decode.
+func verifyStateJSON(g *Game) error {
+	s := *g.State
+	s.TempData = nil
+	want, err := encodeStateJSON(&s)
+	if err != nil {
+		return err
+	}
+
+	viaGob := func(s *State) (*State, error) {
+		data, err := codec.Encode(s)
+		if err != nil {
+			return nil, err
+		}
+		decoded := newState()
+		return decoded, codec.Decode(&decoded, data)
+	}
+
+	fromGob, err := viaGob(&s)
+	if err != nil {
+		return fmt.Errorf("gob: %v", err)
+	}
+	fromJSON, err := decodeStateJSON(want)
+	if err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	fromBoth, err := viaGob(fromJSON)
+	if err != nil {
+		return fmt.Errorf("json to gob: %v", err)
+	}
+
+	for _, trip := range []struct {
+		name string
+		s    *State
+	}{{"gob", fromGob}, {"json", fromJSON}, {"json to gob", fromBoth}} {
+		trip.s.TempData = nil
+		got, err := encodeStateJSON(trip.s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", trip.name, err)
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("%s round trip differs from byte %d", trip.name, firstDifference(got, want))
+		}
+	}
+	return nil
+}
+
+func firstDifference(b1, b2 []byte) int {
+	for i := range b1 {
+		if i >= len(b2) || b1[i] != b2[i] {
+			return i
+		}
+	}
+	return len(b1)
+}
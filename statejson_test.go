@@ -0,0 +1,306 @@
+package indonesia
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SlothNinja/log"
+)
+
+// stateHolder holds a value of a registered type, as the log and the journal do.
+type stateHolder struct {
+	Value interface{}
+}
+
+func encodeStateValue(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	e := new(stateEncoder)
+	if err := e.value(reflect.ValueOf(v)); err != nil {
+		t.Fatalf("encoding %T: %v", v, err)
+	}
+	return e.Bytes()
+}
+
+// decodeStateInto decodes data into a new value of the type of v and returns it.
+func decodeStateInto(t *testing.T, data []byte, v interface{}) interface{} {
+	t.Helper()
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var tree interface{}
+	if err := d.Decode(&tree); err != nil {
+		t.Fatalf("parsing %s: %v", data, err)
+	}
+
+	decoded := reflect.New(reflect.TypeOf(v))
+	if err := decodeStateValue(tree, decoded.Elem()); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return decoded.Elem().Interface()
+}
+
+var stateTime = time.Date(2020, time.January, 2, 3, 4, 5, 6, time.UTC)
+
+// fill sets the fields gob encodes of v to values that are not zero, leaving
+// interfaces nil and pointers nil once depth is spent.
+func fill(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if depth > 0 {
+			v.Set(reflect.New(v.Type().Elem()))
+			fill(v.Elem(), depth-1)
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(stateTime))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if stateField(v.Type().Field(i)) {
+				fill(v.Field(i), depth)
+			}
+		}
+	case reflect.Map:
+		if depth > 0 {
+			k, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			fill(k, depth-1)
+			fill(elem, depth-1)
+			v.Set(reflect.MakeMap(v.Type()))
+			v.SetMapIndex(k, elem)
+		}
+	case reflect.Slice:
+		if depth > 0 {
+			v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+			for i := 0; i < v.Len(); i++ {
+				fill(v.Index(i), depth-1)
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fill(v.Index(i), depth)
+		}
+	case reflect.String:
+		v.SetString("s")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(3)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(3)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
+
+// TestStateJSONRegisteredTypes checks that a filled value of each registered type,
+// such as a log entry or a journaled command, survives the JSON encoding and
+// encodes as the value gob decodes.
+func TestStateJSONRegisteredTypes(t *testing.T) {
+	var names []string
+	for name := range stateTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		typ := stateTypes[name]
+		t.Run(name, func(t *testing.T) {
+			v := reflect.New(typ).Elem()
+			fill(v, 3)
+			holder := stateHolder{Value: v.Interface()}
+			want := encodeStateValue(t, holder)
+			if !strings.Contains(string(want), `"type":"`+name+`"`) {
+				t.Fatalf("encoding %s does not name its type: %s", name, want)
+			}
+
+			fromJSON := decodeStateInto(t, want, holder)
+			if got := encodeStateValue(t, fromJSON); !bytes.Equal(got, want) {
+				t.Errorf("JSON round trip\n got %s\nwant %s", got, want)
+			}
+
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(&holder); err != nil {
+				t.Fatalf("gob: %v", err)
+			}
+			var fromGob stateHolder
+			if err := gob.NewDecoder(&buf).Decode(&fromGob); err != nil {
+				t.Fatalf("gob: %v", err)
+			}
+			if got := encodeStateValue(t, fromGob); !bytes.Equal(got, want) {
+				t.Errorf("gob round trip\n got %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+type sliceFields struct {
+	Nil   []int
+	Empty []int
+	Full  []int
+}
+
+// TestStateJSONValues checks the encodings of values needing care.
+func TestStateJSONValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"integer keys", map[Province]int{Bali: 20, Aceh: 10}, `{"1":10,"2":20}`},
+		{"string keys", map[string]bool{"b": true, "a": false}, `{"a":false,"b":true}`},
+		{
+			"struct keys",
+			flowMatrix{{AreaID: 3, PID: 1}: subflow{{AreaID: 4}: 1}},
+			`[{"key":{"AreaID":3,"PID":1},"value":[{"key":{"AreaID":4},"value":1}]}]`,
+		},
+		{"time", stateTime, `"2020-01-02T03:04:05.000000006Z"`},
+		{"local time", stateTime.In(time.FixedZone("WIB", 7*60*60)), `"2020-01-02T10:04:05.000000006+07:00"`},
+		{"nil slice", []int(nil), `null`},
+		{"empty slice", []int{}, `[]`},
+		{"slice fields", sliceFields{Empty: []int{}, Full: []int{0, 1}}, `{"Full":[0,1]}`},
+		{"bytes", []byte("gob"), `"Z29i"`},
+		{"player", stateHolder{Value: &Player{Rupiah: 5, Bid: NoBid}}, `{"Value":{"type":"Player","value":{"Rupiah":5,"Bid":-1}}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := encodeStateValue(t, test.value)
+			if string(got) != test.want {
+				t.Fatalf("encoded %s, want %s", got, test.want)
+			}
+
+			decoded := decodeStateInto(t, got, test.value)
+			if again := encodeStateValue(t, decoded); !bytes.Equal(again, got) {
+				t.Errorf("decoded value encodes as %s, want %s", again, got)
+			}
+		})
+	}
+}
+
+// TestStateJSONTime checks that decoded times are the same instant in the same offset.
+func TestStateJSONTime(t *testing.T) {
+	local := stateTime.In(time.FixedZone("WIB", 7*60*60))
+	decoded := decodeStateInto(t, encodeStateValue(t, local), local).(time.Time)
+	if !decoded.Equal(local) {
+		t.Errorf("decoded %v, want %v", decoded, local)
+	}
+	if _, offset := decoded.Zone(); offset != 7*60*60 {
+		t.Errorf("decoded offset %d, want %d", offset, 7*60*60)
+	}
+}
+
+// TestStateJSONSlices checks that nil and empty slices of a struct decode alike, as gob decodes them.
+func TestStateJSONSlices(t *testing.T) {
+	decoded := decodeStateInto(t, encodeStateValue(t, sliceFields{Empty: []int{}}), sliceFields{}).(sliceFields)
+	if decoded.Nil != nil || decoded.Empty != nil {
+		t.Errorf("decoded %#v, want nil slices", decoded)
+	}
+
+	decoded = decodeStateInto(t, []byte(`{"Nil":null,"Empty":[]}`), sliceFields{}).(sliceFields)
+	if decoded.Nil != nil {
+		t.Errorf("decoded null as %#v, want nil", decoded.Nil)
+	}
+	if decoded.Empty == nil || len(decoded.Empty) != 0 {
+		t.Errorf("decoded [] as %#v, want an empty slice", decoded.Empty)
+	}
+}
+
+// TestStateJSONHandEdited decodes a state written by hand, with whitespace,
+// fields out of order and fields the state does not have.
+func TestStateJSONHandEdited(t *testing.T) {
+	data := []byte(`{
+  "Seed": 42,
+  "Comment": "fields the state does not have are ignored",
+  "CityStones": [1, 2, 3],
+  "Playerers": [
+    {"type": "Player", "value": {"Bid": 7, "Rupiah": 60}}
+  ],
+  "Era": 1
+}
+`)
+
+	s, err := decodeStateJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Seed != 42 || s.Era != 1 || !reflect.DeepEqual(s.CityStones, []int{1, 2, 3}) {
+		t.Errorf("decoded Seed %d, Era %d, CityStones %v", s.Seed, s.Era, s.CityStones)
+	}
+	if len(s.Playerers) != 1 {
+		t.Fatalf("decoded %d players, want 1", len(s.Playerers))
+	}
+	if p, ok := s.Playerers[0].(*Player); !ok || p.Rupiah != 60 || p.Bid != 7 {
+		t.Errorf("decoded player %#v", s.Playerers[0])
+	}
+
+	for _, bad := range []string{
+		`{"Seed": "42"}`,
+		`{"Playerers": [{"type": "Stranger", "value": {}}]}`,
+		`{"Playerers": [{"value": {}}]}`,
+		`{"Log": [{"type": "Player", "value": {}}]}`,
+	} {
+		if _, err := decodeStateJSON([]byte(bad)); err == nil {
+			t.Errorf("decoded %s, want an error", bad)
+		}
+	}
+}
+
+// TestStateJSONEditedGame changes a field of the state of a game by hand and decodes it.
+func TestStateJSONEditedGame(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	g, err := NewHeadless(1, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.StateJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited := bytes.Replace(data, []byte(`"Seed": 1,`), []byte(`"Seed":   99 ,`), 1)
+	if bytes.Equal(edited, data) {
+		t.Fatal("state has no seed to edit")
+	}
+	s, err := decodeStateJSON(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Seed != 99 {
+		t.Errorf("decoded seed %d, want 99", s.Seed)
+	}
+	if len(s.Areas) != len(g.Areas) || len(s.Playerers) != len(g.Playerers) {
+		t.Errorf("decoded %d areas and %d players, want %d and %d", len(s.Areas), len(s.Playerers), len(g.Areas), len(g.Playerers))
+	}
+}
+
+// TestStateJSONGames checks the encodings of the states reached in games between heuristic bots.
+func TestStateJSONGames(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	for seed := int64(1); seed <= 2; seed++ {
+		g, err := NewHeadless(seed, "a", "b", "c", "d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for step := 0; step < maxBotActions && len(g.CPUserIndices) > 0 && !g.gameOver(); step++ {
+			if step%50 == 0 {
+				if err := verifyStateJSON(g); err != nil {
+					t.Fatalf("seed %d, step %d: %v", seed, step, err)
+				}
+			}
+			pid := g.CPUserIndices[0]
+			if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := verifyStateJSON(g); err != nil {
+			t.Fatalf("seed %d, final state: %v", seed, err)
+		}
+	}
+}
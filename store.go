@@ -0,0 +1,76 @@
+package indonesia
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+// GameStore stores games and the entities saved with them, such as the stats
+// and contests of their players.  Entities are addressed by datastore keys,
+// whichever backend holds them.
+type GameStore interface {
+	// Get loads the entity stored under k into dst.
+	Get(c context.Context, k *datastore.Key, dst interface{}) error
+
+	// RunInTransaction runs f in a transaction.  The puts of f are committed
+	// together if f returns nil and no entity read by f changed meanwhile.
+	RunInTransaction(c context.Context, f func(StoreTx) error) error
+
+	// AllocateID returns a complete key for the incomplete key k.
+	AllocateID(c context.Context, k *datastore.Key) (*datastore.Key, error)
+
+	// Keys returns the keys of the entities of kind descending from ancestor.
+	Keys(c context.Context, kind string, ancestor *datastore.Key) ([]*datastore.Key, error)
+}
+
+// StoreTx is a transaction of a GameStore.  GetMulti loads the entities stored
+// under ks into the elements of dst, a slice of pointers.
+type StoreTx interface {
+	Get(k *datastore.Key, dst interface{}) error
+	GetMulti(ks []*datastore.Key, dst interface{}) error
+	PutMulti(ks []*datastore.Key, es []interface{}) error
+	DeleteMulti(ks []*datastore.Key) error
+}
+
+// datastoreStore keeps games in Cloud Datastore.
+type datastoreStore struct {
+	*datastore.Client
+}
+
+// NewDatastoreStore returns a GameStore backed by the Cloud Datastore client dc.
+func NewDatastoreStore(dc *datastore.Client) GameStore {
+	return datastoreStore{dc}
+}
+
+func (s datastoreStore) Get(c context.Context, k *datastore.Key, dst interface{}) error {
+	return s.Client.Get(c, k, dst)
+}
+
+func (s datastoreStore) RunInTransaction(c context.Context, f func(StoreTx) error) error {
+	_, err := s.Client.RunInTransaction(c, func(tx *datastore.Transaction) error {
+		return f(datastoreTx{tx})
+	})
+	return err
+}
+
+func (s datastoreStore) AllocateID(c context.Context, k *datastore.Key) (*datastore.Key, error) {
+	ks, err := s.Client.AllocateIDs(c, []*datastore.Key{k})
+	if err != nil {
+		return nil, err
+	}
+	return ks[0], nil
+}
+
+func (s datastoreStore) Keys(c context.Context, kind string, ancestor *datastore.Key) ([]*datastore.Key, error) {
+	return s.Client.GetAll(c, datastore.NewQuery(kind).Ancestor(ancestor).KeysOnly(), nil)
+}
+
+type datastoreTx struct {
+	*datastore.Transaction
+}
+
+func (tx datastoreTx) PutMulti(ks []*datastore.Key, es []interface{}) error {
+	_, err := tx.Transaction.PutMulti(ks, es)
+	return err
+}
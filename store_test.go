@@ -0,0 +1,545 @@
+package indonesia
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/codec"
+	"github.com/SlothNinja/log"
+)
+
+type storeEntity struct {
+	Value int
+}
+
+func putEntity(t *testing.T, s GameStore, k *datastore.Key, value int) {
+	t.Helper()
+	err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		return tx.PutMulti([]*datastore.Key{k}, []interface{}{&storeEntity{Value: value}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getEntity(t *testing.T, s GameStore, k *datastore.Key) (int, error) {
+	t.Helper()
+	var e storeEntity
+	err := s.Get(context.Background(), k, &e)
+	return e.Value, err
+}
+
+// increment adds one to the value stored under k in a transaction of s, calling
+// during before each attempt commits.  It returns the number of attempts.
+func increment(s GameStore, k *datastore.Key, during func()) (int, error) {
+	attempts := 0
+	err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		attempts++
+		var e storeEntity
+		if err := tx.Get(k, &e); err != nil && err != datastore.ErrNoSuchEntity {
+			return err
+		}
+		during()
+		e.Value++
+		return tx.PutMulti([]*datastore.Key{k}, []interface{}{&e})
+	})
+	return attempts, err
+}
+
+func TestStoreConflictRetry(t *testing.T) {
+	s := NewMemoryStore()
+	k := datastore.IDKey("Counter", 1, nil)
+	putEntity(t, s, k, 0)
+
+	// A rival transaction commits while the first attempt is running.
+	raced := false
+	attempts, err := increment(s, k, func() {
+		if !raced {
+			raced = true
+			if _, err := increment(s, k, func() {}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("transaction made %d attempts, want 2", attempts)
+	}
+	if got, _ := getEntity(t, s, k); got != 2 {
+		t.Errorf("counter is %d, want 2", got)
+	}
+
+	// A rival transaction commits during every attempt.
+	attempts, err = increment(s, k, func() {
+		if _, err := increment(s, k, func() {}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != datastore.ErrConcurrentTransaction {
+		t.Errorf("transaction failed with %v, want %v", err, datastore.ErrConcurrentTransaction)
+	}
+	if attempts != localStoreAttempts {
+		t.Errorf("transaction made %d attempts, want %d", attempts, localStoreAttempts)
+	}
+	if got, _ := getEntity(t, s, k); got != 2+localStoreAttempts {
+		t.Errorf("counter is %d, want %d", got, 2+localStoreAttempts)
+	}
+}
+
+func TestStoreConflictOnCreate(t *testing.T) {
+	s := NewMemoryStore()
+	k := datastore.IDKey("Counter", 1, nil)
+
+	// The entity is missing when the transaction reads it, and created before it commits.
+	raced := false
+	attempts, err := increment(s, k, func() {
+		if !raced {
+			raced = true
+			putEntity(t, s, k, 10)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("transaction made %d attempts, want 2", attempts)
+	}
+	if got, _ := getEntity(t, s, k); got != 11 {
+		t.Errorf("counter is %d, want 11", got)
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	s := NewMemoryStore()
+	k1, k2 := datastore.IDKey("Thing", 1, nil), datastore.IDKey("Thing", 2, nil)
+	putEntity(t, s, k1, 1)
+	putEntity(t, s, k2, 2)
+
+	err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		return tx.DeleteMulti([]*datastore.Key{k1, datastore.IDKey("Thing", 3, nil)})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := getEntity(t, s, k1); err != datastore.ErrNoSuchEntity {
+		t.Errorf("getting deleted entity returned %v, want %v", err, datastore.ErrNoSuchEntity)
+	}
+	if got, err := getEntity(t, s, k2); err != nil || got != 2 {
+		t.Errorf("getting kept entity returned %d, %v, want 2", got, err)
+	}
+
+	// Deleting and putting an entity in one transaction keeps the last of them.
+	err = s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		if err := tx.DeleteMulti([]*datastore.Key{k2}); err != nil {
+			return err
+		}
+		return tx.PutMulti([]*datastore.Key{k2}, []interface{}{&storeEntity{Value: 5}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := getEntity(t, s, k2); err != nil || got != 5 {
+		t.Errorf("getting replaced entity returned %d, %v, want 5", got, err)
+	}
+
+	if err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		return tx.DeleteMulti([]*datastore.Key{datastore.IncompleteKey("Thing", nil)})
+	}); err == nil {
+		t.Error("deleting an incomplete key succeeded, want an error")
+	}
+}
+
+func TestStoreKeys(t *testing.T) {
+	s := NewMemoryStore()
+	g1, g2 := GameKey(1), GameKey(2)
+	child := datastore.IDKey("Turn", 3, g1)
+	grandchild := datastore.IDKey("Turn", 4, datastore.NameKey("Step", "a", g1))
+	for _, k := range []*datastore.Key{g1, g2, child, grandchild, datastore.IDKey("Turn", 5, g2)} {
+		putEntity(t, s, k, 0)
+	}
+
+	tests := []struct {
+		kind     string
+		ancestor *datastore.Key
+		want     []*datastore.Key
+	}{
+		{"Turn", g1, []*datastore.Key{child, grandchild}},
+		{"Turn", child, []*datastore.Key{child}},
+		{g1.Kind, g1, []*datastore.Key{g1}},
+		{"Step", g2, nil},
+	}
+	for _, test := range tests {
+		ks, err := s.Keys(context.Background(), test.kind, test.ancestor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ks) != len(test.want) {
+			t.Errorf("Keys(%s, %v) = %v, want %v", test.kind, test.ancestor, ks, test.want)
+			continue
+		}
+		for i := range ks {
+			if !ks[i].Equal(test.want[i]) {
+				t.Errorf("Keys(%s, %v) = %v, want %v", test.kind, test.ancestor, ks, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := s.AllocateID(context.Background(), datastore.IncompleteKey("Thing", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := datastore.IDKey("Thing", 7, kept)
+	putEntity(t, s, kept, 1)
+	putEntity(t, s, deleted, 2)
+	if err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		return tx.DeleteMulti([]*datastore.Key{deleted})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := getEntity(t, reloaded, kept); err != nil || got != 1 {
+		t.Errorf("getting reloaded entity returned %d, %v, want 1", got, err)
+	}
+	if _, err := getEntity(t, reloaded, deleted); err != datastore.ErrNoSuchEntity {
+		t.Errorf("getting deleted entity returned %v, want %v", err, datastore.ErrNoSuchEntity)
+	}
+
+	// Ids allocated after reloading stay above the ids stored, deleted ids aside.
+	k, err := reloaded.AllocateID(context.Background(), datastore.IncompleteKey("Thing", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.ID <= kept.ID {
+		t.Errorf("allocated id %d after reloading, want more than %d", k.ID, kept.ID)
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 1 {
+		t.Errorf("store directory holds %d files, want 1", len(fis))
+	}
+}
+
+// TestFileStoreGame imports a game into a file store and loads it after reloading the store.
+func TestFileStoreGame(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g, err := NewHeadless(1, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		pid := g.CPUserIndices[0]
+		if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var want bytes.Buffer
+	if err := g.Export(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := ImportGame(context.Background(), s, bytes.NewReader(want.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame(context.Background(), reloaded, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := loaded.Export(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("reloaded game exports as\n%s\nwant\n%s", got.String(), want.String())
+	}
+}
+
+// playedGame returns a game of three heuristic bots after the given number of actions, keyed by GameKey(1).
+func playedGame(t *testing.T, seed int64, actions int) *Game {
+	t.Helper()
+	g, err := NewHeadless(seed, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Key = GameKey(1)
+	playBots(t, g, actions)
+	return g
+}
+
+// playBots plays at least the given number of actions, and on to the end of a
+// turn, as the temporary data of a turn is not saved.
+func playBots(t *testing.T, g *Game, actions int) {
+	t.Helper()
+	for i := 0; (i < actions || !turnFinished(g)) && !g.gameOver(); i++ {
+		pid := g.CPUserIndices[0]
+		if _, err := g.Apply(pid, HeuristicBot{}.Choose(g, pid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func turnFinished(g *Game) bool {
+	if len(g.Journal) == 0 {
+		return true
+	}
+	_, ok := g.Journal[len(g.Journal)-1].Command.(FinishTurn)
+	return ok
+}
+
+func exportOf(t *testing.T, g *Game) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := g.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// putsTx records the keys of the entities put in a transaction.
+type putsTx struct {
+	StoreTx
+	puts []*datastore.Key
+}
+
+func (tx *putsTx) PutMulti(ks []*datastore.Key, es []interface{}) error {
+	tx.puts = append(tx.puts, ks...)
+	return tx.StoreTx.PutMulti(ks, es)
+}
+
+// putGameWith stores g, recorded of its records being stored, and returns the keys of the record pages put.
+func putGameWith(t *testing.T, s GameStore, g *Game, recorded int) []*datastore.Key {
+	t.Helper()
+	var pages []*datastore.Key
+	err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		ptx := &putsTx{StoreTx: tx}
+		err := putGame(ptx, g, recorded, nil, nil)
+		pages = nil
+		for _, k := range ptx.puts {
+			if k.Kind == recordPageKind {
+				pages = append(pages, k)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pages
+}
+
+// TestStoredJournal checks that journals are stored in record pages apart from
+// the saved state, and that a save puts only the pages of the records it adds.
+func TestStoredJournal(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	for _, encoding := range []string{"", jsonEncoding} {
+		s := NewMemoryStore()
+		g := playedGame(t, 2, 250)
+		g.Encoding = encoding
+		putGameWith(t, s, g, 0)
+
+		ks, err := s.Keys(context.Background(), recordPageKind, g.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (len(g.Journal) + recordsPerPage - 1) / recordsPerPage; len(ks) != want {
+			t.Errorf("%q: stored %d record pages for %d records, want %d", encoding, len(ks), len(g.Journal), want)
+		}
+
+		saved := New(nil, 0)
+		saved.SavedState = g.SavedState
+		if err := saved.decode(); err != nil {
+			t.Fatal(err)
+		}
+		if len(saved.Journal) != 0 || saved.Recorded != len(g.Journal) {
+			t.Errorf("%q: saved state holds %d records and counts %d, want 0 and %d",
+				encoding, len(saved.Journal), saved.Recorded, len(g.Journal))
+		}
+
+		loaded, err := LoadGame(context.Background(), s, g.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := exportOf(t, loaded), exportOf(t, g); got != want {
+			t.Errorf("%q: loaded game exports as\n%s\nwant\n%s", encoding, got, want)
+		}
+
+		recorded := loaded.Recorded
+		playBots(t, loaded, 30)
+		pages := putGameWith(t, s, loaded, recorded)
+		for _, k := range pages {
+			if int(k.ID-1) < recorded/recordsPerPage {
+				t.Errorf("%q: save after record %d put record page %d", encoding, recorded, k.ID-1)
+			}
+		}
+		if len(pages) == 0 {
+			t.Errorf("%q: save put no record pages", encoding)
+		}
+
+		again, err := LoadGame(context.Background(), s, g.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := exportOf(t, again), exportOf(t, loaded); got != want {
+			t.Errorf("%q: reloaded game exports as\n%s\nwant\n%s", encoding, got, want)
+		}
+	}
+}
+
+// putHeader stores the header of g as it is, without encoding its state.
+func putHeader(t *testing.T, s GameStore, g *Game) {
+	t.Helper()
+	err := s.RunInTransaction(context.Background(), func(tx StoreTx) error {
+		return tx.PutMulti([]*datastore.Key{g.Key}, []interface{}{g.Header})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLegacyJournal migrates a game whose saved state holds its journal.
+func TestLegacyJournal(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	s := NewMemoryStore()
+	g := playedGame(t, 3, 150)
+	want := exportOf(t, g)
+
+	g.SchemaVersion = 1
+	encoded, err := codec.Encode(g.State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SavedState = encoded
+	putHeader(t, s, g)
+
+	loaded, err := LoadGame(context.Background(), s, g.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exportOf(t, loaded); got != want {
+		t.Errorf("legacy game exports as\n%s\nwant\n%s", got, want)
+	}
+
+	if from, err := MigrateGame(context.Background(), s, g.Key, false); err != nil || from != 1 {
+		t.Fatalf("migrating returned %d, %v, want 1", from, err)
+	}
+	migrated, err := LoadGame(context.Background(), s, g.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated.Recorded != len(g.Journal) {
+		t.Errorf("migrated game stores %d records, want %d", migrated.Recorded, len(g.Journal))
+	}
+	if got := exportOf(t, migrated); got != want {
+		t.Errorf("migrated game exports as\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestRebuildGame rebuilds a game whose saved state is corrupted from its record pages.
+func TestRebuildGame(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	for _, encoding := range []string{"", jsonEncoding} {
+		s := NewMemoryStore()
+		g := playedGame(t, 4, 220)
+		g.Encoding = encoding
+		putGameWith(t, s, g, 0)
+		want := exportOf(t, g)
+
+		g.SavedState = []byte("corrupted")
+		putHeader(t, s, g)
+		if _, err := LoadGame(context.Background(), s, g.Key); err == nil {
+			t.Fatalf("%q: loading a corrupted game succeeded", encoding)
+		}
+
+		if err := RebuildGame(context.Background(), s, g.Key); err != nil {
+			t.Fatal(err)
+		}
+		rebuilt, err := LoadGame(context.Background(), s, g.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := exportOf(t, rebuilt); got != want {
+			t.Errorf("%q: rebuilt game exports as\n%s\nwant\n%s", encoding, got, want)
+		}
+		if rebuilt.Encoding != g.Encoding {
+			t.Errorf("rebuilt game has encoding %q, want %q", rebuilt.Encoding, g.Encoding)
+		}
+	}
+}
+
+// TestEditedState checks that a state edited by hand keeps the journal, which no longer replays the game.
+func TestEditedState(t *testing.T) {
+	log.DefaultLevel = log.LvlNone
+
+	s := NewMemoryStore()
+	g := playedGame(t, 5, 120)
+	putGameWith(t, s, g, 0)
+
+	data, err := g.StateJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PutStateJSON(context.Background(), s, g.Key, data); err != nil {
+		t.Fatal(err)
+	}
+
+	edited, err := LoadGame(context.Background(), s, g.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Journaled || len(edited.Journal) != len(g.Journal) {
+		t.Errorf("edited game is journaled %v with %d records, want false and %d", edited.Journaled, len(edited.Journal), len(g.Journal))
+	}
+	if err := edited.Replay(); err == nil {
+		t.Error("replaying an edited game succeeded")
+	}
+	var buf bytes.Buffer
+	if err := edited.Export(&buf); err == nil {
+		t.Error("exporting an edited game succeeded")
+	}
+}
@@ -0,0 +1,164 @@
+package indonesia
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+const (
+	turnKind     = "IndonesiaTurn"
+	turnStepKind = "IndonesiaTurnStep"
+)
+
+// turnExpiration is how long a turn left alone is kept by a store turn cache.
+const turnExpiration = 24 * time.Hour
+
+// storeTurns keeps turns in a GameStore, so turns outlast the process and are
+// shared by the processes using the store.  A turn is an entity holding its
+// stack, with a child entity holding the working state after each action.
+type storeTurns struct {
+	s GameStore
+}
+
+// storedTurn is the entity of a turn.  Written counts the steps stored,
+// including steps dropped by a push after an undo, so a reset deletes each.
+type storedTurn struct {
+	turnStack
+	Written   int
+	UpdatedAt time.Time
+}
+
+type storedTurnStep struct {
+	State []byte `datastore:",noindex"`
+}
+
+// NewStoreTurnCache returns a TurnCache keeping turns in s.  Backed by Cloud
+// Datastore, turns are shared by the instances of a server; backed by a memory
+// store, it serves tests.
+func NewStoreTurnCache(s GameStore) TurnCache {
+	return storeTurns{s}
+}
+
+func turnKey(key string) *datastore.Key {
+	return datastore.NameKey(turnKind, key, nil)
+}
+
+func turnStepKey(tk *datastore.Key, step int) *datastore.Key {
+	return datastore.IDKey(turnStepKind, int64(step), tk)
+}
+
+// loadTurn returns the turn stored under tk, expired or not, or nil if there is none.
+func loadTurn(tx StoreTx, tk *datastore.Key) (*storedTurn, error) {
+	t := new(storedTurn)
+	switch err := tx.Get(tk, t); {
+	case err == datastore.ErrNoSuchEntity:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return t, nil
+}
+
+// getTurn returns the turn stored under tk, or nil if there is none or it expired.
+func getTurn(tx StoreTx, tk *datastore.Key) (*storedTurn, error) {
+	t, err := loadTurn(tx, tk)
+	if err != nil || t == nil || t.expired() {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *storedTurn) expired() bool {
+	return time.Since(t.UpdatedAt) > turnExpiration
+}
+
+func (ts storeTurns) Get(c context.Context, key string) ([]byte, bool, error) {
+	var (
+		state []byte
+		found bool
+	)
+	err := ts.s.RunInTransaction(c, func(tx StoreTx) error {
+		tk := turnKey(key)
+		t, err := getTurn(tx, tk)
+		if err != nil || t == nil || t.Current == 0 {
+			return err
+		}
+
+		step := new(storedTurnStep)
+		if err := tx.Get(turnStepKey(tk, t.Current), step); err != nil {
+			return err
+		}
+		state, found = step.State, true
+		return nil
+	})
+	return state, found, err
+}
+
+func (ts storeTurns) Push(c context.Context, key string, state []byte) error {
+	return ts.s.RunInTransaction(c, func(tx StoreTx) error {
+		tk := turnKey(key)
+		t, err := loadTurn(tx, tk)
+		switch {
+		case err != nil:
+			return err
+		case t == nil:
+			t = new(storedTurn)
+		case t.expired():
+			// Start the turn afresh, keeping count of the steps of the
+			// expired turn so a reset still deletes them.
+			t = &storedTurn{Written: t.Written}
+		}
+
+		t.push()
+		t.Written = max(t.Written, t.Current)
+		t.UpdatedAt = time.Now()
+		return tx.PutMulti(
+			[]*datastore.Key{tk, turnStepKey(tk, t.Current)},
+			[]interface{}{t, &storedTurnStep{State: state}},
+		)
+	})
+}
+
+func (ts storeTurns) Undo(c context.Context, key string) (bool, error) {
+	return ts.move(c, key, (*turnStack).undo)
+}
+
+func (ts storeTurns) Redo(c context.Context, key string) (bool, error) {
+	return ts.move(c, key, (*turnStack).redo)
+}
+
+func (ts storeTurns) move(c context.Context, key string, f func(*turnStack) bool) (bool, error) {
+	var moved bool
+	err := ts.s.RunInTransaction(c, func(tx StoreTx) error {
+		tk := turnKey(key)
+		t, err := getTurn(tx, tk)
+		if err != nil || t == nil {
+			return err
+		}
+
+		if moved = f(&t.turnStack); !moved {
+			return nil
+		}
+		t.UpdatedAt = time.Now()
+		return tx.PutMulti([]*datastore.Key{tk}, []interface{}{t})
+	})
+	return moved, err
+}
+
+func (ts storeTurns) Reset(c context.Context, key string) error {
+	return ts.s.RunInTransaction(c, func(tx StoreTx) error {
+		tk := turnKey(key)
+		t, err := loadTurn(tx, tk)
+		if err != nil || t == nil {
+			return err
+		}
+
+		ks := []*datastore.Key{tk}
+		for step := 1; step <= t.Written; step++ {
+			ks = append(ks, turnStepKey(tk, step))
+		}
+		return tx.DeleteMulti(ks)
+	})
+}
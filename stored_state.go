@@ -0,0 +1,349 @@
+package indonesia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/codec"
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/log"
+	"github.com/SlothNinja/sn"
+	"github.com/SlothNinja/user"
+	"github.com/gin-gonic/gin"
+)
+
+// getStored loads the game stored under k, decoding its state and its journal.
+func getStored(tx StoreTx, k *datastore.Key) (*Game, error) {
+	g := New(nil, k.ID)
+	if err := tx.Get(k, g.Header); err != nil {
+		return nil, err
+	}
+
+	if err := g.decode(); err != nil {
+		return nil, err
+	}
+	if err := g.getJournal(tx); err != nil {
+		return nil, err
+	}
+	g.initState()
+	return g, nil
+}
+
+// putStored encodes the state of g and stores g with the records of its journal not yet stored.
+func putStored(tx StoreTx, g *Game) error {
+	return putGame(tx, g, g.Recorded, nil, nil)
+}
+
+// putGame encodes the state of g and stores g, together with the entities es
+// under the keys ks and the record pages holding the records of its journal
+// from record recorded on.  Records before recorded are stored already.
+func putGame(tx StoreTx, g *Game, recorded int, ks []*datastore.Key, es []interface{}) error {
+	if len(g.Journal) < recorded {
+		return fmt.Errorf("journal has %d records; %d are stored", len(g.Journal), recorded)
+	}
+
+	pks, pes, err := g.recordPages(recorded)
+	if err != nil {
+		return err
+	}
+	g.Recorded = len(g.Journal)
+	if err := g.encode(nil); err != nil {
+		return err
+	}
+
+	ks = append(append(ks[:len(ks):len(ks)], pks...), g.Key)
+	es = append(append(es[:len(es):len(es)], pes...), g.Header)
+	return tx.PutMulti(ks, es)
+}
+
+const recordPageKind = "IndonesiaRecordPage"
+
+// recordsPerPage is the number of records held by a record page.
+const recordsPerPage = 100
+
+// recordPage is an entity holding records of the journal of the game it descends
+// from, page n holding the records from record n*recordsPerPage on.  Journals
+// are stored apart from the saved state, so a save writes only the pages of the
+// records it adds, and a state that can not be decoded leaves the journal to
+// rebuild it from.
+type recordPage struct {
+	Records []byte `datastore:",noindex"`
+}
+
+// pageRecords is the content of a record page, encoded as the saved state of
+// its game is.  Each page holds the setup of the journal as it was saved.
+type pageRecords struct {
+	Setup   journalSetup
+	Records Journal
+}
+
+// journalSetup is what replaying a journal takes besides the journal and the
+// header of its game.
+type journalSetup struct {
+	Seed       int64
+	SealedBids bool
+	BotSeats   []BotSeat
+}
+
+func recordPageKey(gk *datastore.Key, page int) *datastore.Key {
+	return datastore.IDKey(recordPageKind, int64(page+1), gk)
+}
+
+// recordPageKeys returns the keys of the first n record pages of the game under gk.
+func recordPageKeys(gk *datastore.Key, n int) []*datastore.Key {
+	ks := make([]*datastore.Key, n)
+	for i := range ks {
+		ks[i] = recordPageKey(gk, i)
+	}
+	return ks
+}
+
+// recordPages returns the keys and entities of the record pages holding the
+// records of the journal of g from record from on.  The page of record from is
+// returned even if it holds no records, so the last page holds the current setup.
+func (g *Game) recordPages(from int) ([]*datastore.Key, []interface{}, error) {
+	var (
+		ks []*datastore.Key
+		es []interface{}
+	)
+	setup := journalSetup{Seed: g.Seed, SealedBids: g.SealedBids, BotSeats: g.BotSeats}
+	first := from / recordsPerPage
+	for page := first; page == first || page*recordsPerPage < len(g.Journal); page++ {
+		rs := pageRecords{Setup: setup}
+		if start := page * recordsPerPage; start < len(g.Journal) {
+			rs.Records = g.Journal[start:min(len(g.Journal), start+recordsPerPage)]
+		}
+
+		var (
+			data []byte
+			err  error
+		)
+		if g.Encoding == jsonEncoding {
+			data, err = encodeJSONValue(&rs)
+		} else {
+			data, err = codec.Encode(&rs)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ks = append(ks, recordPageKey(g.Key, page))
+		es = append(es, &recordPage{Records: data})
+	}
+	return ks, es, nil
+}
+
+// getJournal loads the journal of g from its record pages.  States saved before
+// journals were stored in record pages hold their journals, and store no records.
+func (g *Game) getJournal(tx StoreTx) error {
+	if g.Recorded == 0 || len(g.Journal) > 0 {
+		return nil
+	}
+
+	pages := (g.Recorded + recordsPerPage - 1) / recordsPerPage
+	ps := make([]*recordPage, pages)
+	for i := range ps {
+		ps[i] = new(recordPage)
+	}
+	if err := tx.GetMulti(recordPageKeys(g.Key, pages), ps); err != nil {
+		return err
+	}
+
+	journal := make(Journal, 0, g.Recorded)
+	for i, p := range ps {
+		rs, err := p.decode()
+		if err != nil {
+			return fmt.Errorf("record page %d: %v", i, err)
+		}
+		journal = append(journal, rs.Records...)
+	}
+	if len(journal) < g.Recorded {
+		return fmt.Errorf("record pages hold %d records; %d are stored", len(journal), g.Recorded)
+	}
+	g.Journal = journal[:g.Recorded]
+	return nil
+}
+
+func (p *recordPage) decode() (*pageRecords, error) {
+	rs := new(pageRecords)
+	if isJSONState(p.Records) {
+		return rs, decodeJSONValue(p.Records, rs)
+	}
+	return rs, codec.Decode(rs, p.Records)
+}
+
+// RebuildGame rebuilds the state of the game stored under k by replaying the
+// journal held by its record pages, as when its saved state can not be decoded.
+// The header of the game is kept, and the state is saved in the encoding of
+// the pages.  Edits made outside the journal are lost.
+func RebuildGame(c context.Context, s GameStore, k *datastore.Key) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	ks, err := s.Keys(c, recordPageKind, k)
+	if err != nil {
+		return err
+	}
+	if len(ks) == 0 {
+		return fmt.Errorf("game %v stores no record pages", k)
+	}
+
+	return s.RunInTransaction(c, func(tx StoreTx) error {
+		g := New(nil, k.ID)
+		if err := tx.Get(k, g.Header); err != nil {
+			return err
+		}
+
+		ps := make([]*recordPage, len(ks))
+		for i := range ps {
+			ps[i] = new(recordPage)
+		}
+		if err := tx.GetMulti(recordPageKeys(k, len(ks)), ps); err != nil {
+			return err
+		}
+
+		g.State = newState()
+		for i, p := range ps {
+			rs, err := p.decode()
+			if err != nil {
+				return fmt.Errorf("record page %d: %v", i, err)
+			}
+			g.Journal = append(g.Journal, rs.Records...)
+			g.Seed, g.SealedBids, g.BotSeats = rs.Setup.Seed, rs.Setup.SealedBids, rs.Setup.BotSeats
+			if isJSONState(p.Records) {
+				g.Encoding = jsonEncoding
+			}
+		}
+
+		g.Journaled = true
+		if err := g.Replay(); err != nil {
+			return err
+		}
+		return putGame(tx, g, 0, nil, nil)
+	})
+}
+
+// GameKey returns the key of the game having id.
+func GameKey(id int64) *datastore.Key {
+	return newKey(nil, id)
+}
+
+// LoadGame returns the game stored under k, migrated to the current schema version.
+func LoadGame(c context.Context, s GameStore, k *datastore.Key) (*Game, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	var g *Game
+	err := s.RunInTransaction(c, func(tx StoreTx) (err error) {
+		g, err = getStored(tx, k)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := g.migrate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// StateJSON returns the JSON encoding of the saved state of g, indented for
+// reading.  The journal is stored apart from the saved state, so it is left
+// out; Export writes it.
+func (g *Game) StateJSON() ([]byte, error) {
+	s := *g.State
+	s.TempData, s.Journal = nil, nil
+	data, err := encodeStateJSON(&s)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// PutStateJSON replaces the state of the game stored under k with the state
+// encoded in data, which is migrated to the current schema version.  The
+// state is saved in the encoding it selects.  The journal of the game is kept,
+// but no longer records how the state came about, so the game is no longer
+// journaled.
+func PutStateJSON(c context.Context, s GameStore, k *datastore.Key, data []byte) error {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	state, err := decodeStateJSON(data)
+	if err != nil {
+		return err
+	}
+
+	return s.RunInTransaction(c, func(tx StoreTx) error {
+		g, err := getStored(tx, k)
+		if err != nil {
+			return err
+		}
+
+		state.Journal, state.Recorded = g.Journal, g.Recorded
+		g.State = state
+		g.editedOutsideJournal()
+		g.initState()
+		if _, err := g.migrate(); err != nil {
+			return err
+		}
+		return putStored(tx, g)
+	})
+}
+
+// adminEncoding selects the encoding of the saved state of the game.
+func (g *Game) adminEncoding(c *gin.Context, cu *user.User) (string, game.ActionType, error) {
+	log.Debugf(msgEnter)
+	defer log.Debugf(msgExit)
+
+	err := g.validateAdminAction(cu)
+	if err != nil {
+		return "indonesia/flash_notice", game.None, err
+	}
+
+	switch encoding := c.PostForm("encoding"); encoding {
+	case gobEncoding:
+		g.Encoding = ""
+	case jsonEncoding:
+		g.Encoding = jsonEncoding
+	default:
+		return "indonesia/flash_notice", game.None, sn.NewVError("%q is not a state encoding.", encoding)
+	}
+	return "", game.Save, nil
+}
+
+// stateJSON responds to an admin with the JSON encoding of the state of the game.
+func (client *Client) stateJSON(c *gin.Context) {
+	client.Log.Debugf(msgEnter)
+	defer client.Log.Debugf(msgExit)
+
+	g := gameFrom(c)
+	if g == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	cu, err := client.User.Current(c)
+	if err != nil || !cu.IsAdmin() {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	data, err := g.StateJSON()
+	if err != nil {
+		client.Log.Errorf(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
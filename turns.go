@@ -0,0 +1,204 @@
+package indonesia
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+	"github.com/SlothNinja/codec"
+	"github.com/SlothNinja/game"
+	"github.com/SlothNinja/user"
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+)
+
+// TurnCache holds the turns players have in progress: the working state of a
+// game after each action taken since the game was last saved, and how many of
+// those actions are undone.  Turns are keyed by Header.UndoKey.
+type TurnCache interface {
+	// Get returns the working state after the last action not undone.  It
+	// reports false if there is no turn, or each of its actions is undone.
+	Get(c context.Context, key string) ([]byte, bool, error)
+
+	// Push adds the working state after a new action, dropping the actions undone.
+	Push(c context.Context, key string, state []byte) error
+
+	// Undo undoes the last action not undone, reporting whether there was one.
+	Undo(c context.Context, key string) (bool, error)
+
+	// Redo redoes the first action undone, reporting whether there was one.
+	Redo(c context.Context, key string) (bool, error)
+
+	// Reset drops the turn.
+	Reset(c context.Context, key string) error
+}
+
+// turnStack counts the actions of a turn.  Actions 1 to Current are applied
+// and actions Current+1 to Updated are undone.
+type turnStack struct {
+	Current int
+	Updated int
+}
+
+func (s *turnStack) push() {
+	s.Current++
+	s.Updated = s.Current
+}
+
+func (s *turnStack) undo() bool {
+	undo := s.Current > 0
+	if undo {
+		s.Current--
+	}
+	return undo
+}
+
+func (s *turnStack) redo() bool {
+	redo := s.Current < s.Updated
+	if redo {
+		s.Current++
+	}
+	return redo
+}
+
+// memoryTurns keeps turns in a go-cache, so they last until the cache expires
+// them and are seen only by the process holding the cache.
+type memoryTurns struct {
+	mu    sync.Mutex
+	cache *cache.Cache
+}
+
+// memoryTurn is a turn of memoryTurns.  States[i] is the working state after action i+1.
+type memoryTurn struct {
+	turnStack
+	States [][]byte
+}
+
+// NewMemoryTurnCache returns a TurnCache keeping turns in the cache ch.
+func NewMemoryTurnCache(ch *cache.Cache) TurnCache {
+	return &memoryTurns{cache: ch}
+}
+
+func (ts *memoryTurns) turn(key string) *memoryTurn {
+	if item, found := ts.cache.Get(key); found {
+		if t, ok := item.(*memoryTurn); ok {
+			return t
+		}
+	}
+	return nil
+}
+
+func (ts *memoryTurns) Get(c context.Context, key string) ([]byte, bool, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	t := ts.turn(key)
+	if t == nil || t.Current == 0 {
+		return nil, false, nil
+	}
+	return t.States[t.Current-1], true, nil
+}
+
+func (ts *memoryTurns) Push(c context.Context, key string, state []byte) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	t := ts.turn(key)
+	if t == nil {
+		t = new(memoryTurn)
+	}
+	t.States = append(t.States[:t.Current:t.Current], state)
+	t.push()
+	ts.cache.SetDefault(key, t)
+	return nil
+}
+
+func (ts *memoryTurns) Undo(c context.Context, key string) (bool, error) {
+	return ts.move(key, (*turnStack).undo)
+}
+
+func (ts *memoryTurns) Redo(c context.Context, key string) (bool, error) {
+	return ts.move(key, (*turnStack).redo)
+}
+
+func (ts *memoryTurns) move(key string, f func(*turnStack) bool) (bool, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	t := ts.turn(key)
+	if t == nil || !f(&t.turnStack) {
+		return false, nil
+	}
+	ts.cache.SetDefault(key, t)
+	return true, nil
+}
+
+func (ts *memoryTurns) Reset(c context.Context, key string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.cache.Delete(key)
+	return nil
+}
+
+// workingState is the working state of a game held by a turn cache: the
+// properties of its header, less the saved state, and its state with the
+// temporary data of the turn.
+type workingState struct {
+	Header []datastore.Property
+	State  *State
+}
+
+// encodeTurn encodes the working state of g.
+func (g *Game) encodeTurn() ([]byte, error) {
+	saved := g.SavedState
+	g.SavedState = nil
+	ps, err := datastore.SaveStruct(g.Header)
+	g.SavedState = saved
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(workingState{Header: gobProperties(ps), State: g.State})
+}
+
+// decodeTurn restores the working state of g encoded by encodeTurn.
+func (g *Game) decodeTurn(data []byte) error {
+	var w workingState
+	if err := codec.Decode(&w, data); err != nil {
+		return err
+	}
+
+	k := g.Key
+	if err := datastore.LoadStruct(g.Header, w.Header); err != nil {
+		return err
+	}
+	g.Key = k
+
+	if w.State.TempData == nil {
+		w.State.TempData = new(TempData)
+	}
+	g.State = w.State
+	return nil
+}
+
+// updateTurn records the action of type at, taken by cu, in the turn of cu.
+func (client *Client) updateTurn(c *gin.Context, g *Game, cu *user.User, at game.ActionType) error {
+	key := g.UndoKey(cu)
+	switch at {
+	case game.Cache:
+		state, err := g.encodeTurn()
+		if err != nil {
+			return err
+		}
+		return client.Turns.Push(c, key, state)
+	case game.Undo:
+		_, err := client.Turns.Undo(c, key)
+		return err
+	case game.Redo:
+		_, err := client.Turns.Redo(c, key)
+		return err
+	case game.Reset:
+		return client.Turns.Reset(c, key)
+	}
+	return nil
+}
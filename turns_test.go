@@ -0,0 +1,187 @@
+package indonesia
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/patrickmn/go-cache"
+)
+
+// turnCaches returns each kind of TurnCache, the store turn cache backed by a memory store.
+func turnCaches() map[string]TurnCache {
+	return map[string]TurnCache{
+		"memory": NewMemoryTurnCache(cache.New(cache.NoExpiration, 0)),
+		"store":  NewStoreTurnCache(NewMemoryStore()),
+	}
+}
+
+// wantTurn checks that the working state of the turn under key is want, or that there is none if want is empty.
+func wantTurn(t *testing.T, ts TurnCache, key, want string) {
+	t.Helper()
+	state, found, err := ts.Get(context.Background(), key)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case want == "" && found:
+		t.Errorf("turn holds %q, want none", state)
+	case want != "" && string(state) != want:
+		t.Errorf("turn holds %q, found %v, want %q", state, found, want)
+	}
+}
+
+func TestTurnCache(t *testing.T) {
+	for name, ts := range turnCaches() {
+		t.Run(name, func(t *testing.T) {
+			c, key := context.Background(), "turn"
+			push := func(state string) {
+				t.Helper()
+				if err := ts.Push(c, key, []byte(state)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			move := func(f func(context.Context, string) (bool, error), want bool) {
+				t.Helper()
+				moved, err := f(c, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if moved != want {
+					t.Errorf("moved %v, want %v", moved, want)
+				}
+			}
+
+			wantTurn(t, ts, key, "")
+			move(ts.Undo, false)
+			move(ts.Redo, false)
+
+			push("a")
+			push("b")
+			push("c")
+			wantTurn(t, ts, key, "c")
+
+			move(ts.Undo, true)
+			move(ts.Undo, true)
+			wantTurn(t, ts, key, "a")
+			move(ts.Redo, true)
+			wantTurn(t, ts, key, "b")
+			move(ts.Redo, true)
+			move(ts.Redo, false)
+			wantTurn(t, ts, key, "c")
+
+			move(ts.Undo, true)
+			move(ts.Undo, true)
+			move(ts.Undo, true)
+			move(ts.Undo, false)
+			wantTurn(t, ts, key, "")
+
+			// A push after an undo drops the actions undone.
+			move(ts.Redo, true)
+			push("d")
+			wantTurn(t, ts, key, "d")
+			move(ts.Redo, false)
+			move(ts.Undo, true)
+			wantTurn(t, ts, key, "a")
+
+			wantTurn(t, ts, "other", "")
+
+			if err := ts.Reset(c, key); err != nil {
+				t.Fatal(err)
+			}
+			wantTurn(t, ts, key, "")
+			move(ts.Undo, false)
+			if err := ts.Reset(c, key); err != nil {
+				t.Fatal(err)
+			}
+
+			push("e")
+			wantTurn(t, ts, key, "e")
+			move(ts.Undo, true)
+			move(ts.Undo, false)
+		})
+	}
+}
+
+// TestTurnCacheConcurrent pushes to and resets turns at once.  Run with -race.
+func TestTurnCacheConcurrent(t *testing.T) {
+	for name, ts := range turnCaches() {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					c, key := context.Background(), fmt.Sprintf("turn-%d", i%2)
+					for j := 0; j < 50; j++ {
+						var err error
+						if j%10 == 9 {
+							err = ts.Reset(c, key)
+						} else {
+							err = ts.Push(c, key, []byte{byte(j)})
+						}
+						// Rival transactions of the store may exhaust their attempts.
+						if err != nil && err != datastore.ErrConcurrentTransaction {
+							t.Error(err)
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// TestStoreTurnExpired checks that a push to an expired turn starts the turn
+// afresh and that a reset then deletes the steps of both.
+func TestStoreTurnExpired(t *testing.T) {
+	s := NewMemoryStore()
+	ts := NewStoreTurnCache(s)
+	c, key := context.Background(), "turn"
+	tk := turnKey(key)
+
+	for _, state := range []string{"a", "b", "c"} {
+		if err := ts.Push(c, key, []byte(state)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := s.RunInTransaction(c, func(tx StoreTx) error {
+		t, err := loadTurn(tx, tk)
+		if err != nil {
+			return err
+		}
+		t.UpdatedAt = time.Now().Add(-turnExpiration - time.Minute)
+		return tx.PutMulti([]*datastore.Key{tk}, []interface{}{t})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTurn(t, ts, key, "")
+
+	if err := ts.Push(c, key, []byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	wantTurn(t, ts, key, "d")
+	if moved, err := ts.Redo(c, key); err != nil || moved {
+		t.Errorf("redo after expiry returned %v, %v, want false", moved, err)
+	}
+	if moved, err := ts.Undo(c, key); err != nil || !moved {
+		t.Errorf("undo after expiry returned %v, %v, want true", moved, err)
+	}
+	if moved, err := ts.Undo(c, key); err != nil || moved {
+		t.Errorf("second undo after expiry returned %v, %v, want false", moved, err)
+	}
+
+	if err := ts.Reset(c, key); err != nil {
+		t.Fatal(err)
+	}
+	ks, err := s.Keys(c, turnStepKind, tk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ks) != 0 {
+		t.Errorf("reset left steps %v", ks)
+	}
+}
@@ -48,16 +48,22 @@ func (z *Zone) adjacentAreas(tests ...addAreaTest) Areas {
 }
 
 func (z *Zone) adjacentToArea(area *Area) bool {
-	return z.adjacentAreas().include(area)
+	return area != nil && z.g.neighborhood(z.set()).has(area.ID)
 }
 
 func (z *Zone) adjacentToZone(zone *Zone) bool {
-	for _, area := range zone.Areas() {
-		if z.adjacentToArea(area) {
-			return true
-		}
+	return z.g.neighborhood(z.set()).intersects(zone.set())
+}
+
+func (z *Zone) set() areaSet {
+	return setOf(z.AreaIDS...)
+}
+
+func (zs Zones) set() (s areaSet) {
+	for _, z := range zs {
+		s = s.union(z.set())
 	}
-	return false
+	return
 }
 
 func (z *Zone) same(zone *Zone) bool {
@@ -73,15 +79,6 @@ func (zs Zones) include(zone *Zone) bool {
 	return false
 }
 
-func (z *Zone) overlaps(zone *Zone) bool {
-	for _, aid := range zone.AreaIDS {
-		if z.AreaIDS.include(aid) {
-			return true
-		}
-	}
-	return false
-}
-
 func (zs Zones) intersection(zones Zones) Zones {
 	var common Zones
 	for _, zone := range zones {
@@ -92,31 +89,6 @@ func (zs Zones) intersection(zones Zones) Zones {
 	return common
 }
 
-func (zs Zones) addZone(zone *Zone) Zones {
-	var zonesToMerge, zones Zones
-	for _, z := range zs {
-		if z.overlaps(zone) || z.adjacentToZone(zone) {
-			zonesToMerge = append(zonesToMerge, z)
-		} else {
-			zones = append(zones, z)
-		}
-	}
-	if len(zonesToMerge) == 0 {
-		return append(zs, zone)
-	}
-	for _, z := range zonesToMerge {
-		zone.AreaIDS = zone.AreaIDS.addUnique(z.AreaIDS...)
-	}
-	return append(zones, zone)
-}
-
-func (zs Zones) addZones(zones ...*Zone) Zones {
-	for _, zone := range zones {
-		zs = zs.addZone(zone)
-	}
-	return zs
-}
-
 func (z *Zone) Goods() Goods {
 	if area := z.g.Areas[z.AreaIDS[0]]; area != nil {
 		return area.Goods()
@@ -157,3 +129,159 @@ func (zs Zones) contiguous() bool {
 	}
 	return true
 }
+
+// zoneForest is a disjoint-set forest over the areas of a company, each set
+// being a zone of the company.  It is built from the Zones of the company when
+// first needed and rewrites them whenever it changes, so Zones remains the
+// persisted form.
+type zoneForest struct {
+	c      *Company
+	areas  areaSet
+	parent [numAreaIDs]AreaID
+	size   [numAreaIDs]int
+
+	// zone holds the index in c.Zones of the zone rooted at each area.
+	zone [numAreaIDs]int
+}
+
+func (c *Company) zoneForest() *zoneForest {
+	if c.forest == nil {
+		c.forest = newZoneForest(c)
+	}
+	return c.forest
+}
+
+func newZoneForest(c *Company) *zoneForest {
+	f := &zoneForest{c: c}
+	for i, zone := range c.Zones {
+		if len(zone.AreaIDS) == 0 {
+			continue
+		}
+		root := zone.AreaIDS[0]
+		for _, id := range zone.AreaIDS {
+			if validAreaID(id) && !f.areas.has(id) {
+				f.areas.add(id)
+				f.parent[id] = root
+				f.size[root]++
+			}
+		}
+		f.zone[root] = i
+	}
+	return f
+}
+
+func validAreaID(id AreaID) bool {
+	return id >= 0 && int(id) < numAreaIDs
+}
+
+func (f *zoneForest) find(id AreaID) AreaID {
+	for f.parent[id] != id {
+		f.parent[id] = f.parent[f.parent[id]]
+		id = f.parent[id]
+	}
+	return id
+}
+
+func (f *zoneForest) union(id1, id2 AreaID) {
+	root1, root2 := f.find(id1), f.find(id2)
+	if root1 == root2 {
+		return
+	}
+	if f.size[root1] < f.size[root2] {
+		root1, root2 = root2, root1
+	}
+	f.parent[root2] = root1
+	f.size[root1] += f.size[root2]
+}
+
+// zoneFor returns the zone containing the area having id aid, if any.
+func (f *zoneForest) zoneFor(aid AreaID) *Zone {
+	if !f.areas.has(aid) {
+		return nil
+	}
+	return f.c.Zones[f.zone[f.find(aid)]]
+}
+
+// add places the area having id aid in its own zone and merges that zone with
+// the zones of the adjacent areas producing the same goods.
+func (f *zoneForest) add(aid AreaID) {
+	if !validAreaID(aid) || f.areas.has(aid) {
+		return
+	}
+	f.areas.add(aid)
+	f.parent[aid], f.size[aid] = aid, 1
+	f.join(aid, f.areas)
+	f.rezone()
+}
+
+// remove drops the area having id aid, splitting its zone into the parts left
+// connected.
+func (f *zoneForest) remove(aid AreaID) {
+	if !f.areas.has(aid) {
+		return
+	}
+	root := f.find(aid)
+	var members areaSet
+	for _, id := range f.areas.ids() {
+		if f.find(id) == root {
+			members.add(id)
+		}
+	}
+	members.remove(aid)
+	f.areas.remove(aid)
+	for _, id := range members.ids() {
+		f.parent[id], f.size[id] = id, 1
+	}
+	for _, id := range members.ids() {
+		f.join(id, members)
+	}
+	f.rezone()
+}
+
+// regroup rebuilds the zones from the adjacency of the areas alone.
+func (f *zoneForest) regroup() {
+	areas := f.areas
+	for _, id := range areas.ids() {
+		f.parent[id], f.size[id] = id, 1
+	}
+	for _, id := range areas.ids() {
+		f.join(id, areas)
+	}
+	f.rezone()
+}
+
+// join merges the zone of the area having id aid with the zones of the
+// adjacent areas of s producing the same goods.
+func (f *zoneForest) join(aid AreaID, s areaSet) {
+	g := f.c.g
+	area := g.GetArea(aid)
+	if area == nil {
+		return
+	}
+	for _, id := range g.neighbors(aid).intersect(s).ids() {
+		if a := g.GetArea(id); a != nil && a.Goods() == area.Goods() {
+			f.union(aid, id)
+		}
+	}
+}
+
+// rezone rewrites the Zones of the company from the forest, ordering the zones
+// and their areas by area id.
+func (f *zoneForest) rezone() {
+	var (
+		zones Zones
+		seen  areaSet
+	)
+	for _, id := range f.areas.ids() {
+		root := f.find(id)
+		if seen.has(root) {
+			zone := zones[f.zone[root]]
+			zone.AreaIDS = append(zone.AreaIDS, id)
+			continue
+		}
+		seen.add(root)
+		f.zone[root] = len(zones)
+		zones = append(zones, newZone(f.c.g, AreaIDS{id}))
+	}
+	f.c.Zones = zones
+}